@@ -1,7 +1,6 @@
 package modules
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"strconv"
@@ -17,16 +16,14 @@ func GetUsersAndPasswordsCombo(h *Host, combo string, version string) ([]string,
 	userSlice := []string{}
 	passSlice := []string{}
 
-	if IsFile(combo) {
-		file, err := os.Open(combo)
+	if IsWordlistSourceSpec(combo) {
+		scanner, reader, err := openSourceScanner(combo)
 		if err != nil {
-			fmt.Println("Error opening combo file:", err)
+			fmt.Println("Error opening combo source:", err)
 			os.Exit(1)
 		}
-		defer file.Close()
+		defer reader.Close()
 
-		scanner := bufio.NewScanner(file)
-		scanner.Buffer(make([]byte, DefaultScannerBufferSize), MaxLineLength)
 		for scanner.Scan() {
 			line := scanner.Text()
 			if strings.Contains(line, ":") {
@@ -34,12 +31,12 @@ func GetUsersAndPasswordsCombo(h *Host, combo string, version string) ([]string,
 				userSlice = append(userSlice, splits[0])
 				passSlice = append(passSlice, splits[1])
 			} else {
-				fmt.Printf("Invalid format in combo file: %s\n", line)
+				fmt.Printf("Invalid format in combo source: %s\n", line)
 				os.Exit(1)
 			}
 		}
 		if err := scanner.Err(); err != nil {
-			fmt.Println("Error reading combo file:", err)
+			fmt.Println("Error reading combo source:", err)
 			os.Exit(1)
 		}
 	} else {
@@ -51,17 +48,51 @@ func GetUsersAndPasswordsCombo(h *Host, combo string, version string) ([]string,
 	return userSlice, passSlice
 }
 
+// readWordlistSourceLines resolves spec via the WordlistSource abstraction
+// (plain file, http(s)://, stdin, mem://) and reads every line into a slice.
+func readWordlistSourceLines(spec string) ([]string, error) {
+	scanner, reader, err := openSourceScanner(spec)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// isStdinSpec reports whether spec names standard input, mirroring the
+// cases ResolveWordlistSource treats as StdinSource.
+func isStdinSpec(spec string) bool {
+	return spec == "-" || spec == "stdin"
+}
+
 func GetUsersAndPasswords(h *Host, user string, password string, version string) ([]string, []string) {
+	if isStdinSpec(user) && isStdinSpec(password) {
+		// Both goroutines below would read os.Stdin concurrently with no
+		// coordination, scrambling lines between the user and password
+		// slices - there's no sane way to split one stdin stream into two
+		// wordlists, so refuse rather than silently corrupt both.
+		fmt.Println("Error: user and password cannot both be read from stdin")
+		os.Exit(1)
+	}
+
 	userCh := make(chan string)
 	passCh := make(chan string)
 
 	go func() {
 		defer close(userCh)
 		if user != "" {
-			if IsFile(user) {
-				users, err := ReadUsersFromFile(user)
+			if IsWordlistSourceSpec(user) {
+				users, err := readWordlistSourceLines(user)
 				if err != nil {
-					fmt.Println("Error reading user file:", err)
+					fmt.Println("Error reading user source:", err)
 					os.Exit(1)
 				}
 				for _, u := range users {
@@ -81,10 +112,10 @@ func GetUsersAndPasswords(h *Host, user string, password string, version string)
 	go func() {
 		defer close(passCh)
 		if password != "" {
-			if IsFile(password) {
-				passwords, err := ReadPasswordsFromFile(password)
+			if IsWordlistSourceSpec(password) {
+				passwords, err := readWordlistSourceLines(password)
 				if err != nil {
-					fmt.Println("Error reading password file:", err)
+					fmt.Println("Error reading password source:", err)
 					os.Exit(1)
 				}
 				for _, p := range passwords {
@@ -160,9 +191,11 @@ func CalcCombinationsCombo(userCh []string, passCh []string) int {
 	return totalCombinations
 }
 
-// GetCredentialIterator creates an iterator for streaming credentials
-func GetCredentialIterator(h *Host, user, password, combo, version string, isPasswordOnly bool) (*CredentialIterator, error) {
-	return NewCredentialIterator(h, user, password, combo, version, isPasswordOnly)
+// GetCredentialIterator creates an iterator for streaming credentials.
+// shardIndex/shardCount split the credential space across cooperating
+// instances; pass 0, 1 to disable sharding.
+func GetCredentialIterator(h *Host, user, password, combo, version string, isPasswordOnly bool, shardIndex, shardCount int) (*CredentialIterator, error) {
+	return NewCredentialIterator(h, user, password, combo, version, isPasswordOnly, shardIndex, shardCount)
 }
 
 // CountCredentials counts total credentials without loading them all into memory
@@ -173,17 +206,15 @@ func CountCredentials(h *Host, user, password, combo, version string, isPassword
 
 	if combo != "" {
 		// Count combo credentials
-		if IsFile(combo) {
-			fmt.Fprintf(os.Stderr, "[*] Counting combo file: %s\n", combo)
-			file, err := os.Open(combo)
+		if IsWordlistSourceSpec(combo) {
+			fmt.Fprintf(os.Stderr, "[*] Counting combo source: %s\n", combo)
+			scanner, reader, err := openSourceScanner(combo)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error opening combo file for counting: %v\n", err)
+				fmt.Fprintf(os.Stderr, "Error opening combo source for counting: %v\n", err)
 				return 0
 			}
-			defer file.Close()
+			defer reader.Close()
 
-			scanner := bufio.NewScanner(file)
-			scanner.Buffer(make([]byte, DefaultScannerBufferSize), MaxLineLength)
 			for scanner.Scan() {
 				line := scanner.Text()
 				splits := strings.SplitN(line, ":", 2)
@@ -195,7 +226,7 @@ func CountCredentials(h *Host, user, password, combo, version string, isPassword
 				}
 			}
 			if err := scanner.Err(); err != nil {
-				fmt.Fprintf(os.Stderr, "Error reading combo file for counting: %v\n", err)
+				fmt.Fprintf(os.Stderr, "Error reading combo source for counting: %v\n", err)
 				return 0
 			}
 			fmt.Fprintf(os.Stderr, "[*] Total combo lines: %d\n", count)
@@ -210,17 +241,15 @@ func CountCredentials(h *Host, user, password, combo, version string, isPassword
 	if isPasswordOnly {
 		userCount = 1 // Password-only services use empty user
 	} else if user != "" {
-		if IsFile(user) {
-			fmt.Fprintf(os.Stderr, "[*] Counting user file: %s\n", user)
-			file, err := os.Open(user)
+		if IsWordlistSourceSpec(user) {
+			fmt.Fprintf(os.Stderr, "[*] Counting user source: %s\n", user)
+			scanner, reader, err := openSourceScanner(user)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error opening user file for counting: %v\n", err)
+				fmt.Fprintf(os.Stderr, "Error opening user source for counting: %v\n", err)
 				return 0
 			}
-			defer file.Close()
+			defer reader.Close()
 
-			scanner := bufio.NewScanner(file)
-			scanner.Buffer(make([]byte, DefaultScannerBufferSize), MaxLineLength)
 			for scanner.Scan() {
 				userCount++
 				if userCount%10000 == 0 {
@@ -228,7 +257,7 @@ func CountCredentials(h *Host, user, password, combo, version string, isPassword
 				}
 			}
 			if err := scanner.Err(); err != nil {
-				fmt.Fprintf(os.Stderr, "Error reading user file for counting: %v\n", err)
+				fmt.Fprintf(os.Stderr, "Error reading user source for counting: %v\n", err)
 				return 0
 			}
 			fmt.Fprintf(os.Stderr, "[*] Total users: %d\n", userCount)
@@ -245,17 +274,15 @@ func CountCredentials(h *Host, user, password, combo, version string, isPassword
 	// Count passwords
 	passCount := 0
 	if password != "" {
-		if IsFile(password) {
-			fmt.Fprintf(os.Stderr, "[*] Counting password file: %s (this may take a while for large files)\n", password)
-			file, err := os.Open(password)
+		if IsWordlistSourceSpec(password) {
+			fmt.Fprintf(os.Stderr, "[*] Counting password source: %s (this may take a while for large files)\n", password)
+			scanner, reader, err := openSourceScanner(password)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error opening password file for counting: %v\n", err)
+				fmt.Fprintf(os.Stderr, "Error opening password source for counting: %v\n", err)
 				return 0
 			}
-			defer file.Close()
+			defer reader.Close()
 
-			scanner := bufio.NewScanner(file)
-			scanner.Buffer(make([]byte, DefaultScannerBufferSize), MaxLineLength)
 			for scanner.Scan() {
 				passCount++
 				if passCount%100000 == 0 {
@@ -263,7 +290,7 @@ func CountCredentials(h *Host, user, password, combo, version string, isPassword
 				}
 			}
 			if err := scanner.Err(); err != nil {
-				fmt.Fprintf(os.Stderr, "Error reading password file for counting: %v\n", err)
+				fmt.Fprintf(os.Stderr, "Error reading password source for counting: %v\n", err)
 				return 0
 			}
 			fmt.Fprintf(os.Stderr, "[*] Total passwords: %d\n", passCount)
@@ -0,0 +1,134 @@
+package modules
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CheckpointSchemaVersion is bumped whenever the on-disk Checkpoint layout
+// changes in a way that is not backwards compatible.
+const CheckpointSchemaVersion = 1
+
+// DefaultCheckpointInterval is how many credentials are yielded by Next()
+// between checkpoint writes when the caller doesn't override it.
+const DefaultCheckpointInterval = 1000
+
+// Checkpoint captures enough state to resume a CredentialIterator from the
+// exact position it left off at, across a crash, Ctrl-C, or target timeout.
+type Checkpoint struct {
+	SchemaVersion int    `json:"schema_version"`
+	Host          string `json:"host"`
+	Port          int    `json:"port"`
+	Service       string `json:"service"`
+
+	// Mode mirrors the iterator's mode so a resume can detect a mismatched
+	// checkpoint (e.g. re-run with -C after an earlier -u/-p run) and refuse
+	// to apply it instead of silently misbehaving.
+	Mode string `json:"mode"`
+
+	// Byte offsets into the respective source files, measured in bytes
+	// consumed (including the trailing newline) by the scanner so far.
+	UserFileOffset     int64 `json:"user_file_offset"`
+	PasswordFileOffset int64 `json:"password_file_offset"`
+	ComboFileOffset    int64 `json:"combo_file_offset"`
+
+	// In-memory slice cursors, used when users/passwords/combo come from a
+	// single value or a small slice rather than a file.
+	UserIndex int `json:"user_index"`
+	PassIndex int `json:"pass_index"`
+
+	// CurrentUser is the user whose password list was in progress when the
+	// checkpoint was written. Needed because UserFileOffset already points
+	// past this user's line in the user file (it advances as soon as the
+	// user is read, before its passwords are tried) - without it a resume
+	// would jump straight to the next user instead of finishing this one.
+	CurrentUser string `json:"current_user"`
+
+	// ShardIndex/ShardCount record which slice of the credential space this
+	// iterator was assigned (see CredentialIterator.inShard). A resume only
+	// applies if they match the current run's flags, so a node can't
+	// accidentally replay another node's slice after a config change.
+	ShardIndex int `json:"shard_index"`
+	ShardCount int `json:"shard_count"`
+
+	// ComboLineIndex is the number of combo lines seen so far, used to
+	// resume combo-mode sharding (which shards by line number, not by hash)
+	// at the right point in the modulo sequence.
+	ComboLineIndex int64 `json:"combo_line_index"`
+}
+
+const (
+	checkpointModeStandard     = "standard"
+	checkpointModePasswordOnly = "password-only"
+	checkpointModeCombo        = "combo"
+)
+
+// LoadCheckpoint reads and decodes a checkpoint file. A missing file is not
+// an error: callers should treat it the same as "no checkpoint yet".
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading checkpoint: %w", err)
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("error parsing checkpoint: %w", err)
+	}
+
+	if cp.SchemaVersion != CheckpointSchemaVersion {
+		return nil, fmt.Errorf("checkpoint schema version %d is not supported (expected %d)", cp.SchemaVersion, CheckpointSchemaVersion)
+	}
+
+	return &cp, nil
+}
+
+// Save atomically rewrites the checkpoint file via a temp-file + rename so a
+// crash mid-write never leaves a corrupt checkpoint behind.
+func (cp *Checkpoint) Save(path string) error {
+	cp.SchemaVersion = CheckpointSchemaVersion
+
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding checkpoint: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, ".checkpoint-*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating temp checkpoint file: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("error writing temp checkpoint file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("error closing temp checkpoint file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("error renaming temp checkpoint file: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCheckpoint removes a checkpoint file, ignoring the case where it
+// doesn't exist.
+func DeleteCheckpoint(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package modules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestResumableCredentialIteratorSurvivesRestart kills a standard-mode
+// iterator partway through the password file for the second user and
+// verifies that resuming from the checkpoint yields exactly the remaining
+// credentials: no duplicates, no gaps.
+func TestResumableCredentialIteratorSurvivesRestart(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "brutespray-checkpoint-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	passFile := filepath.Join(tmpDir, "passwords.txt")
+	f, err := os.Create(passFile)
+	if err != nil {
+		t.Fatalf("Failed to create password file: %v", err)
+	}
+	totalPasswords := 200
+	for i := 0; i < totalPasswords; i++ {
+		fmt.Fprintf(f, "pass%d\n", i)
+	}
+	f.Close()
+
+	userFile := filepath.Join(tmpDir, "users.txt")
+	f, err = os.Create(userFile)
+	if err != nil {
+		t.Fatalf("Failed to create user file: %v", err)
+	}
+	totalUsers := 3
+	for i := 0; i < totalUsers; i++ {
+		fmt.Fprintf(f, "user%d\n", i)
+	}
+	f.Close()
+
+	host := &Host{Host: "127.0.0.1", Port: 22, Service: "ssh"}
+	checkpointPath := filepath.Join(tmpDir, "checkpoint.json")
+
+	seen := make(map[string]bool)
+
+	// Run the first iterator and "kill" it partway through, as if the
+	// process died mid-run. checkpointInterval=1 so every credential is
+	// durably recorded before we stop.
+	iter1, err := NewResumableCredentialIterator(host, userFile, passFile, "", "1.0", false, 0, 1, checkpointPath, 1)
+	if err != nil {
+		t.Fatalf("Failed to create resumable iterator: %v", err)
+	}
+
+	stopAfter := totalPasswords + 50 // partway into the second user's passwords
+	for i := 0; i < stopAfter; i++ {
+		u, p, ok := iter1.Next()
+		if !ok {
+			t.Fatalf("Iterator ended early at credential %d", i)
+		}
+		key := u + ":" + p
+		if seen[key] {
+			t.Fatalf("Duplicate credential before simulated crash: %s", key)
+		}
+		seen[key] = true
+	}
+	// Simulate a crash: do not call Close(), which would otherwise persist
+	// or delete the checkpoint in an orderly way.
+
+	if _, err := os.Stat(checkpointPath); err != nil {
+		t.Fatalf("Expected checkpoint file to exist after running: %v", err)
+	}
+
+	// Resume on a fresh iterator pointed at the same checkpoint.
+	iter2, err := NewResumableCredentialIterator(host, userFile, passFile, "", "1.0", false, 0, 1, checkpointPath, 1)
+	if err != nil {
+		t.Fatalf("Failed to create resumed iterator: %v", err)
+	}
+
+	for {
+		u, p, ok := iter2.Next()
+		if !ok {
+			break
+		}
+		key := u + ":" + p
+		if seen[key] {
+			t.Errorf("Resumed iterator replayed credential %s", key)
+		}
+		seen[key] = true
+	}
+	iter2.Close()
+
+	expectedTotal := totalUsers * totalPasswords
+	if len(seen) != expectedTotal {
+		t.Errorf("Expected %d total unique credentials across both runs, got %d", expectedTotal, len(seen))
+	}
+
+	if _, err := os.Stat(checkpointPath); !os.IsNotExist(err) {
+		t.Errorf("Expected checkpoint to be removed after exhausting the iterator, stat err: %v", err)
+	}
+}
+
+// TestResumableCredentialIteratorSurvivesRestartAcrossUserBoundary uses a
+// checkpointInterval far larger than the run, so the only checkpoint write
+// that happens at all is the immediate one saveCheckpointNow triggers when
+// an exhausted user's password pass finishes. It kills the run one
+// credential into the second user and verifies the resumed run starts
+// there - not back at the start of the first user's password list - which
+// is the scenario a too-early checkpoint save would get wrong.
+func TestResumableCredentialIteratorSurvivesRestartAcrossUserBoundary(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "brutespray-checkpoint-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	passFile := filepath.Join(tmpDir, "passwords.txt")
+	f, err := os.Create(passFile)
+	if err != nil {
+		t.Fatalf("Failed to create password file: %v", err)
+	}
+	totalPasswords := 5
+	for i := 0; i < totalPasswords; i++ {
+		fmt.Fprintf(f, "pass%d\n", i)
+	}
+	f.Close()
+
+	userFile := filepath.Join(tmpDir, "users.txt")
+	f, err = os.Create(userFile)
+	if err != nil {
+		t.Fatalf("Failed to create user file: %v", err)
+	}
+	totalUsers := 3
+	for i := 0; i < totalUsers; i++ {
+		fmt.Fprintf(f, "user%d\n", i)
+	}
+	f.Close()
+
+	host := &Host{Host: "127.0.0.1", Port: 22, Service: "ssh"}
+	checkpointPath := filepath.Join(tmpDir, "checkpoint.json")
+
+	seen := make(map[string]bool)
+
+	// checkpointInterval=1000 means maybeCheckpoint's periodic write never
+	// fires during this short run; the only thing that can persist progress
+	// is the immediate save that happens when user0's password pass ends.
+	iter1, err := NewResumableCredentialIterator(host, userFile, passFile, "", "1.0", false, 0, 1, checkpointPath, 1000)
+	if err != nil {
+		t.Fatalf("Failed to create resumable iterator: %v", err)
+	}
+
+	// Stop one credential into user1, just past the user0->user1 boundary.
+	stopAfter := totalPasswords + 1
+	for i := 0; i < stopAfter; i++ {
+		u, p, ok := iter1.Next()
+		if !ok {
+			t.Fatalf("Iterator ended early at credential %d", i)
+		}
+		key := u + ":" + p
+		if seen[key] {
+			t.Fatalf("Duplicate credential before simulated crash: %s", key)
+		}
+		seen[key] = true
+	}
+	// Simulate a crash: do not call Close().
+
+	iter2, err := NewResumableCredentialIterator(host, userFile, passFile, "", "1.0", false, 0, 1, checkpointPath, 1000)
+	if err != nil {
+		t.Fatalf("Failed to create resumed iterator: %v", err)
+	}
+
+	for {
+		u, p, ok := iter2.Next()
+		if !ok {
+			break
+		}
+		key := u + ":" + p
+		if seen[key] {
+			t.Errorf("Resumed iterator replayed credential %s", key)
+		}
+		seen[key] = true
+	}
+	iter2.Close()
+
+	expectedTotal := totalUsers * totalPasswords
+	if len(seen) != expectedTotal {
+		t.Errorf("Expected %d total unique credentials across both runs, got %d", expectedTotal, len(seen))
+	}
+}
@@ -0,0 +1,226 @@
+package modules
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// KeepChunks disables chunk cache cleanup entirely (the --keep-chunks flag),
+// leaving every cache directory on disk so a later run against the same
+// wordlist reuses it instantly instead of rebuilding it.
+var KeepChunks = false
+
+// ChunkManifestSchemaVersion is bumped whenever the on-disk ChunkManifest
+// layout changes in a way that is not backwards compatible.
+const ChunkManifestSchemaVersion = 1
+
+// chunkManifestName marks a cache entry as complete; its absence means a
+// previous chunking attempt was interrupted and the directory isn't usable.
+const chunkManifestName = "manifest.json"
+
+// chunkRefsDirName holds one empty file per holder of a cache entry (named
+// after the holding process's pid; one process may hold several), so
+// Cleanup knows whether it's the last holder.
+const chunkRefsDirName = "refs"
+
+// ChunkManifest records everything needed to safely reuse a previously
+// chunked wordlist.
+type ChunkManifest struct {
+	SchemaVersion int      `json:"schema_version"`
+	ChunkPaths    []string `json:"chunk_paths"`
+	LineCounts    []int    `json:"line_counts"`
+	SHA256        []string `json:"sha256"`
+}
+
+// chunkCacheRoot returns ~/.cache/brutespray/chunks (or the platform
+// equivalent), falling back to a temp directory if it can't be determined.
+func chunkCacheRoot() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "brutespray-cache", "chunks")
+	}
+	return filepath.Join(cacheDir, "brutespray", "chunks")
+}
+
+// chunkCacheKey hashes the source wordlist's path, size, and modification
+// time into a stable cache directory name. Any of those changing (the file
+// was edited, replaced, or moved) produces a different key, so a stale
+// cache entry is never mistaken for a fresh one.
+func chunkCacheKey(path string, size int64, modTimeUnixNano int64) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s|%d|%d", path, size, modTimeUnixNano)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// loadChunkManifest reads a cache entry's manifest. A missing manifest
+// means the entry isn't complete (or doesn't exist yet) rather than an
+// error; an unreadable or corrupt one is returned as an error so the caller
+// can decide whether to log it and rebuild.
+func loadChunkManifest(dir string) (*ChunkManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, chunkManifestName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading chunk manifest: %w", err)
+	}
+
+	var m ChunkManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("error parsing chunk manifest: %w", err)
+	}
+	if m.SchemaVersion != ChunkManifestSchemaVersion {
+		return nil, fmt.Errorf("chunk manifest schema version %d is not supported (expected %d)", m.SchemaVersion, ChunkManifestSchemaVersion)
+	}
+
+	return &m, nil
+}
+
+// Save atomically writes the manifest via a temp-file + rename, so a crash
+// mid-write never leaves a directory that looks complete but isn't.
+func (m *ChunkManifest) Save(dir string) error {
+	m.SchemaVersion = ChunkManifestSchemaVersion
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding chunk manifest: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".manifest-*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating temp manifest file: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("error writing temp manifest file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("error closing temp manifest file: %w", err)
+	}
+	if err := os.Rename(tmpPath, filepath.Join(dir, chunkManifestName)); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("error renaming temp manifest file: %w", err)
+	}
+
+	return nil
+}
+
+// sha256File hashes a file's contents, used to populate a ChunkManifest.
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("error opening %s for hashing: %w", path, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("error hashing %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// chunkBlobDirName holds the content-addressed chunk store shared by every
+// cache entry under chunkCacheRoot(): a chunk whose bytes are byte-for-byte
+// identical to one built for a previous (or differently-edited) wordlist is
+// stored once and referenced from both manifests, so re-chunking a wordlist
+// after a small edit only writes the chunks that actually changed.
+const chunkBlobDirName = "blobs"
+
+// blobPath returns where a chunk with the given sha256 lives in the shared
+// blob store.
+func blobPath(sha256Sum string) string {
+	return filepath.Join(chunkCacheRoot(), chunkBlobDirName, sha256Sum)
+}
+
+// storeBlob moves the chunk file at tmpPath into the shared blob store under
+// its content hash, returning the blob's final path. If a blob with that
+// hash already exists (an unchanged chunk reused across an edit), tmpPath is
+// discarded instead of overwriting it - the existing content is identical by
+// definition of sha256Sum.
+func storeBlob(tmpPath, sha256Sum string) (string, error) {
+	dest := blobPath(sha256Sum)
+
+	if _, err := os.Stat(dest); err == nil {
+		os.Remove(tmpPath)
+		return dest, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return "", fmt.Errorf("error creating chunk blob directory: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, dest); err != nil {
+		// Rename fails across filesystems (e.g. tmpPath and the cache root
+		// aren't on the same device); fall back to a copy.
+		src, openErr := os.Open(tmpPath)
+		if openErr != nil {
+			return "", fmt.Errorf("error reopening chunk file to store it: %w", openErr)
+		}
+		copyErr := CopyReaderToFile(src, dest)
+		src.Close()
+		if copyErr != nil {
+			return "", fmt.Errorf("error storing chunk blob: %w", copyErr)
+		}
+		os.Remove(tmpPath)
+	}
+
+	return dest, nil
+}
+
+// addChunkCacheRef registers the calling process as a holder of cacheDir by
+// creating a uniquely named file under its refs/ subdirectory. Callers must
+// hold cacheDir's lock.
+func addChunkCacheRef(cacheDir string) error {
+	refsDir := filepath.Join(cacheDir, chunkRefsDirName)
+	if err := os.MkdirAll(refsDir, 0755); err != nil {
+		return fmt.Errorf("error creating chunk cache refs directory: %w", err)
+	}
+
+	ref, err := os.CreateTemp(refsDir, fmt.Sprintf("pid%d-*.ref", os.Getpid()))
+	if err != nil {
+		return fmt.Errorf("error creating chunk cache ref file: %w", err)
+	}
+	return ref.Close()
+}
+
+// removeChunkCacheRef removes one ref file belonging to the calling process
+// and reports whether no refs remain afterward, meaning the caller is the
+// last holder and may remove cacheDir entirely. Callers must hold
+// cacheDir's lock.
+func removeChunkCacheRef(cacheDir string) (empty bool, err error) {
+	refsDir := filepath.Join(cacheDir, chunkRefsDirName)
+	entries, err := os.ReadDir(refsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return true, nil
+		}
+		return false, fmt.Errorf("error reading chunk cache refs directory: %w", err)
+	}
+
+	prefix := fmt.Sprintf("pid%d-", os.Getpid())
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), prefix) {
+			if err := os.Remove(filepath.Join(refsDir, e.Name())); err != nil && !os.IsNotExist(err) {
+				return false, fmt.Errorf("error removing chunk cache ref file: %w", err)
+			}
+			break
+		}
+	}
+
+	entries, err = os.ReadDir(refsDir)
+	if err != nil {
+		return false, fmt.Errorf("error reading chunk cache refs directory: %w", err)
+	}
+	return len(entries) == 0, nil
+}
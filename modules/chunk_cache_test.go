@@ -0,0 +1,258 @@
+package modules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestChunkCacheKeyStability verifies the cache key only changes when the
+// path, size, or mtime it's derived from changes.
+func TestChunkCacheKeyStability(t *testing.T) {
+	mtime := time.Now().UnixNano()
+
+	k1 := chunkCacheKey("/tmp/passwords.txt", 1000, mtime)
+	k2 := chunkCacheKey("/tmp/passwords.txt", 1000, mtime)
+	if k1 != k2 {
+		t.Error("Expected identical inputs to produce the same cache key")
+	}
+
+	if k3 := chunkCacheKey("/tmp/passwords.txt", 2000, mtime); k3 == k1 {
+		t.Error("Expected a different size to change the cache key")
+	}
+	if k4 := chunkCacheKey("/tmp/passwords.txt", 1000, mtime+1); k4 == k1 {
+		t.Error("Expected a different mtime to change the cache key")
+	}
+	if k5 := chunkCacheKey("/tmp/other.txt", 1000, mtime); k5 == k1 {
+		t.Error("Expected a different path to change the cache key")
+	}
+}
+
+// TestChunkManifestSaveLoad round-trips a manifest through disk.
+func TestChunkManifestSaveLoad(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "brutespray-manifest-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if m, err := loadChunkManifest(tmpDir); err != nil || m != nil {
+		t.Fatalf("Expected no manifest yet, got %v, %v", m, err)
+	}
+
+	want := &ChunkManifest{
+		ChunkPaths: []string{"chunk_0000.txt", "chunk_0001.txt"},
+		LineCounts: []int{10, 5},
+		SHA256:     []string{"aaaa", "bbbb"},
+	}
+	if err := want.Save(tmpDir); err != nil {
+		t.Fatalf("Failed to save manifest: %v", err)
+	}
+
+	got, err := loadChunkManifest(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to load manifest: %v", err)
+	}
+	if got == nil || len(got.ChunkPaths) != 2 || got.ChunkPaths[1] != "chunk_0001.txt" || got.LineCounts[0] != 10 {
+		t.Errorf("Loaded manifest does not match what was saved: %+v", got)
+	}
+}
+
+// TestAcquireLockExcludesConcurrentHolders verifies a second AcquireLock
+// against the same path blocks until the first is released.
+func TestAcquireLockExcludesConcurrentHolders(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "brutespray-lock-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	lockPath := filepath.Join(tmpDir, "test.lock")
+
+	first, err := AcquireLock(lockPath)
+	if err != nil {
+		t.Fatalf("Failed to acquire first lock: %v", err)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		second, err := AcquireLock(lockPath)
+		if err != nil {
+			t.Errorf("Failed to acquire second lock: %v", err)
+			return
+		}
+		close(acquired)
+		second.Release()
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("Second AcquireLock returned before the first was released")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if err := first.Release(); err != nil {
+		t.Fatalf("Failed to release first lock: %v", err)
+	}
+
+	select {
+	case <-acquired:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Second AcquireLock never returned after the first was released")
+	}
+}
+
+// TestChunkedFileCacheReuseAndRefcount verifies two ChunkedFiles chunking
+// the same source reuse one cache directory, and that it's only removed
+// once both have called Cleanup.
+func TestChunkedFileCacheReuseAndRefcount(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "brutespray-cache-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	sourcePath := filepath.Join(tmpDir, "passwords.txt")
+	f, err := os.Create(sourcePath)
+	if err != nil {
+		t.Fatalf("Failed to create source file: %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(f, "pass%d\n", i)
+	}
+	f.Close()
+
+	// acquireChunks is called directly (bypassing NewChunkedFile's size
+	// threshold, so the global LargeFileThreshold/FileChunkSize stay
+	// untouched) with a tiny ChunkSize so this small file still gets split
+	// into several chunks.
+	newHolder := func() *ChunkedFile {
+		cf := &ChunkedFile{OriginalPath: sourcePath, ChunkSize: 50}
+		if err := cf.acquireChunks(); err != nil {
+			t.Fatalf("acquireChunks failed: %v", err)
+		}
+		return cf
+	}
+
+	holder1 := newHolder()
+	holder2 := newHolder()
+
+	if holder1.CacheDir != holder2.CacheDir {
+		t.Fatalf("Expected both holders to share one cache directory, got %s and %s", holder1.CacheDir, holder2.CacheDir)
+	}
+	if len(holder1.ChunkPaths) < 2 {
+		t.Fatalf("Expected the source file to be split into multiple chunks, got %d", len(holder1.ChunkPaths))
+	}
+	cacheDir := holder1.CacheDir
+
+	if err := holder1.Cleanup(); err != nil {
+		t.Fatalf("holder1.Cleanup failed: %v", err)
+	}
+	if _, err := os.Stat(cacheDir); err != nil {
+		t.Fatalf("Expected cache dir to survive while holder2 still holds it: %v", err)
+	}
+
+	if err := holder2.Cleanup(); err != nil {
+		t.Fatalf("holder2.Cleanup failed: %v", err)
+	}
+	if _, err := os.Stat(cacheDir); !os.IsNotExist(err) {
+		t.Errorf("Expected cache dir to be removed after the last holder's Cleanup, got err=%v", err)
+	}
+}
+
+// TestChunkedFileKeepChunks verifies KeepChunks bypasses cache deletion.
+func TestChunkedFileKeepChunks(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "brutespray-cache-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	sourcePath := filepath.Join(tmpDir, "passwords.txt")
+	f, err := os.Create(sourcePath)
+	if err != nil {
+		t.Fatalf("Failed to create source file: %v", err)
+	}
+	for i := 0; i < 50; i++ {
+		fmt.Fprintf(f, "pass%d\n", i)
+	}
+	f.Close()
+
+	cf := &ChunkedFile{OriginalPath: sourcePath, ChunkSize: 50}
+	if err := cf.acquireChunks(); err != nil {
+		t.Fatalf("acquireChunks failed: %v", err)
+	}
+	cacheDir := cf.CacheDir
+
+	KeepChunks = true
+	defer func() { KeepChunks = false }()
+
+	if err := cf.Cleanup(); err != nil {
+		t.Fatalf("Cleanup failed: %v", err)
+	}
+	if _, err := os.Stat(cacheDir); err != nil {
+		t.Errorf("Expected cache dir to survive Cleanup when KeepChunks is set: %v", err)
+	}
+
+	os.RemoveAll(cacheDir)
+}
+
+// TestChunkedFileKeepChunksStillReleasesRef verifies a KeepChunks holder
+// still drops its own ref on Cleanup, so a later, ordinary (non-keep-chunks)
+// holder of the same cache directory can still reach an empty refcount and
+// remove it - KeepChunks should only ever affect whether *its own* Cleanup
+// removes the directory, not whether the refcount stays accurate for
+// whoever comes next.
+func TestChunkedFileKeepChunksStillReleasesRef(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "brutespray-cache-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	sourcePath := filepath.Join(tmpDir, "passwords.txt")
+	f, err := os.Create(sourcePath)
+	if err != nil {
+		t.Fatalf("Failed to create source file: %v", err)
+	}
+	for i := 0; i < 50; i++ {
+		fmt.Fprintf(f, "pass%d\n", i)
+	}
+	f.Close()
+
+	newHolder := func() *ChunkedFile {
+		cf := &ChunkedFile{OriginalPath: sourcePath, ChunkSize: 50}
+		if err := cf.acquireChunks(); err != nil {
+			t.Fatalf("acquireChunks failed: %v", err)
+		}
+		return cf
+	}
+
+	// Process A runs with --keep-chunks and cleans up.
+	KeepChunks = true
+	holderA := newHolder()
+	cacheDir := holderA.CacheDir
+	if err := holderA.Cleanup(); err != nil {
+		t.Fatalf("holderA.Cleanup failed: %v", err)
+	}
+	if _, err := os.Stat(cacheDir); err != nil {
+		t.Fatalf("Expected cache dir to survive a keep-chunks Cleanup: %v", err)
+	}
+	KeepChunks = false
+
+	// Process B runs a completely ordinary acquire+cleanup against the same
+	// source afterward; it should still be able to remove the cache
+	// directory once it releases its own ref.
+	holderB := newHolder()
+	if holderB.CacheDir != cacheDir {
+		t.Fatalf("Expected holderB to reuse the same cache directory, got %s", holderB.CacheDir)
+	}
+	if err := holderB.Cleanup(); err != nil {
+		t.Fatalf("holderB.Cleanup failed: %v", err)
+	}
+	if _, err := os.Stat(cacheDir); !os.IsNotExist(err) {
+		t.Errorf("Expected cache dir to be removed once the keep-chunks holder's ref was released and holderB cleaned up, got err=%v", err)
+	}
+}
@@ -0,0 +1,379 @@
+package modules
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// matrixMemCeilingBytes bounds the HeapAlloc growth sampled during a
+// matrix row's iteration. It's generous enough to absorb ordinary allocator
+// noise on the small fixtures these rows use, but would catch a regression
+// where a row that's supposed to stream (instead of buffering a whole
+// wordlist) stops doing so.
+const matrixMemCeilingBytes = 64 * 1024 * 1024
+
+// userPassUserCount is the fixed user-list size used by "userPass" rows, so
+// the expected combination count is just userPassUserCount*lines regardless
+// of how many password lines a row's size tier uses.
+const userPassUserCount = 5
+
+// matrixCase is one row of the chunking x source x mode x size matrix (see
+// TestChunkingSourceModeMatrix). Adding a new source kind or mode only needs
+// a new entry in the slices buildMatrixCases loops over, not a new test
+// function.
+type matrixCase struct {
+	chunkingDisabled bool
+	sourceKind       string // "plainFile", "gzipFile", "stdinPipe", "memSource", "httpSource"
+	mode             string // "userPass", "passwordOnly", "combo"
+	large            bool   // forces real multi-chunk splitting via a shrunk threshold
+}
+
+func (c matrixCase) name() string {
+	return fmt.Sprintf("chunking=%v/source=%s/mode=%s/large=%v", !c.chunkingDisabled, c.sourceKind, c.mode, c.large)
+}
+
+func buildMatrixCases() []matrixCase {
+	var cases []matrixCase
+	for _, chunkingDisabled := range []bool{false, true} {
+		for _, kind := range []string{"plainFile", "gzipFile", "stdinPipe", "memSource", "httpSource"} {
+			for _, mode := range []string{"userPass", "passwordOnly", "combo"} {
+				for _, large := range []bool{false, true} {
+					cases = append(cases, matrixCase{
+						chunkingDisabled: chunkingDisabled,
+						sourceKind:       kind,
+						mode:             mode,
+						large:            large,
+					})
+				}
+			}
+		}
+	}
+	return cases
+}
+
+// TestChunkingSourceModeMatrix exercises every combination of chunking
+// on/off, source kind, credential mode, and file size tier. plainFile and
+// gzipFile rows drive the full CredentialIterator (the same path production
+// code takes) and separately verify the underlying ChunkedFile splits (or
+// doesn't) as expected - CredentialIterator itself always streams via a
+// plain scanner regardless of DisableFileChunking, so exercising
+// NewChunkedFile directly against the same file is what actually proves a
+// large wordlist gets chunked. stdinPipe/memSource/httpSource rows drive
+// NewChunkedFileFromSource instead (CredentialIterator's handling of those
+// specs is covered in credential_iterator_source_test.go) and
+// assert the same three properties: exact line count, a memory ceiling
+// sampled during iteration, and no leftover chunk files after Cleanup.
+func TestChunkingSourceModeMatrix(t *testing.T) {
+	origDisable := DisableFileChunking
+	origThreshold, origChunkSize := LargeFileThreshold, FileChunkSize
+	defer func() {
+		DisableFileChunking = origDisable
+		LargeFileThreshold = origThreshold
+		FileChunkSize = origChunkSize
+	}()
+
+	for _, c := range buildMatrixCases() {
+		c := c
+		t.Run(c.name(), func(t *testing.T) {
+			DisableFileChunking = c.chunkingDisabled
+			if c.large {
+				// Shrink both knobs so a modest fixture still crosses the
+				// chunking threshold and splits into several chunks,
+				// instead of needing a multi-GB file to exercise the same
+				// path.
+				LargeFileThreshold = 2048
+				FileChunkSize = 512
+			} else {
+				LargeFileThreshold = origThreshold
+				FileChunkSize = origChunkSize
+			}
+
+			lines := 50
+			if c.large {
+				lines = 400
+			}
+
+			switch c.sourceKind {
+			case "plainFile", "gzipFile":
+				runFileBackedMatrixCase(t, c, lines)
+			default:
+				runStreamBackedMatrixCase(t, c, lines)
+			}
+		})
+	}
+}
+
+// sampleMaxHeapAlloc calls tick once to set up, then calls the function it
+// returns until that reports false, sampling runtime.MemStats.HeapAlloc
+// every sampleEvery of those calls, and returns the highest value observed
+// minus the baseline taken just before tick was called.
+func sampleMaxHeapAlloc(t *testing.T, tick func() func() bool) int64 {
+	t.Helper()
+
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+	peak := before.HeapAlloc
+
+	next := tick()
+	i := 0
+	const sampleEvery = 25
+	for next() {
+		i++
+		if i%sampleEvery == 0 {
+			var m runtime.MemStats
+			runtime.ReadMemStats(&m)
+			if m.HeapAlloc > peak {
+				peak = m.HeapAlloc
+			}
+		}
+	}
+
+	if peak < before.HeapAlloc {
+		return 0
+	}
+	return int64(peak - before.HeapAlloc)
+}
+
+func writeMatrixFixture(t *testing.T, dir, name string, content []byte, gzipIt bool) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if !gzipIt {
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+		return path
+	}
+
+	path += ".gz"
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create %s: %v", path, err)
+	}
+	gw := gzip.NewWriter(f)
+	if _, err := gw.Write(content); err != nil {
+		t.Fatalf("Failed to write gzip content: %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("Failed to close gzip writer: %v", err)
+	}
+	f.Close()
+	return path
+}
+
+func runFileBackedMatrixCase(t *testing.T, c matrixCase, lines int) {
+	tmpDir, err := os.MkdirTemp("", "brutespray-matrix-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	gzipIt := c.sourceKind == "gzipFile"
+	host := &Host{Host: "127.0.0.1", Port: 22, Service: "ssh"}
+
+	var passFile, userFile, comboFile string
+	var expected int
+	isPasswordOnly := false
+
+	var passBuf, comboBuf bytes.Buffer
+	for i := 0; i < lines; i++ {
+		fmt.Fprintf(&passBuf, "pass%d\n", i)
+		fmt.Fprintf(&comboBuf, "user%d:pass%d\n", i, i)
+	}
+
+	switch c.mode {
+	case "userPass":
+		passFile = writeMatrixFixture(t, tmpDir, "passwords.txt", passBuf.Bytes(), gzipIt)
+		var userBuf bytes.Buffer
+		for i := 0; i < userPassUserCount; i++ {
+			fmt.Fprintf(&userBuf, "user%d\n", i)
+		}
+		userFile = writeMatrixFixture(t, tmpDir, "users.txt", userBuf.Bytes(), gzipIt)
+		expected = userPassUserCount * lines
+	case "passwordOnly":
+		passFile = writeMatrixFixture(t, tmpDir, "passwords.txt", passBuf.Bytes(), gzipIt)
+		isPasswordOnly = true
+		expected = lines
+	case "combo":
+		comboFile = writeMatrixFixture(t, tmpDir, "combos.txt", comboBuf.Bytes(), gzipIt)
+		expected = lines
+	}
+
+	var iter *CredentialIterator
+	count := 0
+	peak := sampleMaxHeapAlloc(t, func() func() bool {
+		var err error
+		iter, err = NewCredentialIterator(host, userFile, passFile, comboFile, "1.0", isPasswordOnly, 0, 1)
+		if err != nil {
+			t.Fatalf("Failed to create iterator: %v", err)
+		}
+		return func() bool {
+			u, p, ok := iter.Next()
+			if !ok {
+				return false
+			}
+			if c.mode != "passwordOnly" && u == "" {
+				t.Error("Got empty user")
+			}
+			if p == "" {
+				t.Error("Got empty password")
+			}
+			count++
+			return true
+		}
+	})
+	if err := iter.Close(); err != nil {
+		t.Fatalf("iter.Close failed: %v", err)
+	}
+
+	if peak > matrixMemCeilingBytes {
+		t.Errorf("Expected streaming memory growth under %d bytes, got %d", matrixMemCeilingBytes, peak)
+	}
+	if count != expected {
+		t.Errorf("Expected %d combinations, got %d", expected, count)
+	}
+
+	// ChunkedFile only decompresses content when it actually splits into
+	// chunks (acquireChunks reads through openWordlist); its non-chunked
+	// fast path hands back the original file verbatim, compressed or not.
+	// So exercising NewChunkedFile's own chunk/no-chunk split - and reading
+	// the resulting chunk content back - only gives a predictable line
+	// count against the plain fixture. The gzip fixture is still fully
+	// exercised above, through the same decompression path CredentialIterator
+	// uses in production.
+	if gzipIt {
+		return
+	}
+
+	sourcePath := passFile
+	if sourcePath == "" {
+		sourcePath = comboFile
+	}
+
+	cf, err := NewChunkedFile(sourcePath)
+	if err != nil {
+		t.Fatalf("NewChunkedFile failed: %v", err)
+	}
+
+	wantChunked := c.large && !c.chunkingDisabled
+	if cf.IsChunked != wantChunked {
+		t.Errorf("Expected IsChunked=%v, got %v", wantChunked, cf.IsChunked)
+	}
+	if wantChunked && len(cf.ChunkPaths) < 2 {
+		t.Errorf("Expected multiple chunks for a large wordlist, got %d", len(cf.ChunkPaths))
+	}
+
+	lineCount, err := CountLinesInChunkedFile(cf)
+	if err != nil {
+		t.Fatalf("CountLinesInChunkedFile failed: %v", err)
+	}
+	if lineCount != lines {
+		t.Errorf("Expected %d lines from ChunkedFile, got %d", lines, lineCount)
+	}
+
+	assertChunkedFileCleansUp(t, cf)
+}
+
+func runStreamBackedMatrixCase(t *testing.T, c matrixCase, lines int) {
+	var content bytes.Buffer
+	for i := 0; i < lines; i++ {
+		if c.mode == "combo" {
+			fmt.Fprintf(&content, "user%d:pass%d\n", i, i)
+		} else {
+			fmt.Fprintf(&content, "pass%d\n", i)
+		}
+	}
+
+	var source WordlistSource
+	switch c.sourceKind {
+	case "stdinPipe":
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatalf("Failed to create pipe: %v", err)
+		}
+		origStdin := os.Stdin
+		os.Stdin = r
+		t.Cleanup(func() { os.Stdin = origStdin })
+		go func() {
+			io.Copy(w, bytes.NewReader(content.Bytes()))
+			w.Close()
+		}()
+		source = StdinSource{}
+
+	case "memSource":
+		key := "matrix-" + c.name()
+		RegisterMemWordlist(key, content.Bytes())
+		t.Cleanup(func() { UnregisterMemWordlist(key) })
+		source = MemSource{Key: key}
+
+	case "httpSource":
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write(content.Bytes())
+		}))
+		t.Cleanup(server.Close)
+		httpSrc := HTTPSource{URL: server.URL + "/wordlist.txt"}
+		t.Cleanup(func() { os.Remove(httpSrc.cachePath()) })
+		source = httpSrc
+	}
+
+	var cf *ChunkedFile
+	var lineCount int
+	peak := sampleMaxHeapAlloc(t, func() func() bool {
+		var err error
+		cf, err = NewChunkedFileFromSource(source)
+		if err != nil {
+			t.Fatalf("NewChunkedFileFromSource failed: %v", err)
+		}
+		done := false
+		return func() bool {
+			if done {
+				return false
+			}
+			done = true
+			var err error
+			lineCount, err = CountLinesInChunkedFile(cf)
+			if err != nil {
+				t.Fatalf("CountLinesInChunkedFile failed: %v", err)
+			}
+			return false
+		}
+	})
+
+	if lineCount != lines {
+		t.Errorf("Expected %d lines, got %d", lines, lineCount)
+	}
+	if peak > matrixMemCeilingBytes {
+		t.Errorf("Expected streaming memory growth under %d bytes, got %d", matrixMemCeilingBytes, peak)
+	}
+
+	assertChunkedFileCleansUp(t, cf)
+}
+
+// assertChunkedFileCleansUp calls cf.Cleanup() and verifies whichever of
+// CacheDir/TempDir it was holding is actually gone afterward.
+func assertChunkedFileCleansUp(t *testing.T, cf *ChunkedFile) {
+	t.Helper()
+
+	cacheDir, tmpDir := cf.CacheDir, cf.TempDir
+	if err := cf.Cleanup(); err != nil {
+		t.Fatalf("Cleanup failed: %v", err)
+	}
+	if cacheDir != "" {
+		if _, err := os.Stat(cacheDir); !os.IsNotExist(err) {
+			t.Errorf("Expected chunk cache dir to be removed after Cleanup, got err=%v", err)
+		}
+	}
+	if tmpDir != "" {
+		if _, err := os.Stat(tmpDir); !os.IsNotExist(err) {
+			t.Errorf("Expected chunk temp dir to be removed after Cleanup, got err=%v", err)
+		}
+	}
+}
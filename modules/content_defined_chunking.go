@@ -0,0 +1,74 @@
+package modules
+
+// rollingWindowSize is the number of trailing bytes the content-defined
+// chunk boundary hash is computed over.
+const rollingWindowSize = 64
+
+// contentChunkBoundaryMask is tested against the rolling hash after every
+// line; a match declares a chunk boundary. Because the test runs once per
+// line rather than once per byte, boundaries land on average every 1<<21
+// lines (not bytes) of content, independent of anything
+// written earlier in the file - the same rollsum-style approach
+// containers/storage uses to dedup chunked image layers. Var rather than
+// const so tests can shrink it to exercise multi-chunk splits on small
+// fixtures.
+var contentChunkBoundaryMask uint64 = (1 << 21) - 1
+
+// minContentChunkSize and FileChunkSize (the existing byte-split ceiling)
+// clamp the rolling hash's natural boundary placement so an unlucky (or
+// lucky) run of hashes can't produce a degenerate number of tiny or
+// oversized chunks. Var for the same reason as contentChunkBoundaryMask.
+var minContentChunkSize int64 = 16 * 1024 * 1024
+
+// rollingChunker is a Rabin-style polynomial rolling hash over the trailing
+// rollingWindowSize bytes fed to it, used to pick content-defined chunk
+// boundaries. It's kept running continuously across chunk boundaries (not
+// reset per-chunk) so that a boundary's position only depends on the 64
+// bytes immediately preceding it - editing the file upstream shifts which
+// chunk a boundary falls in, but not whether that boundary recurs, which is
+// what lets unaffected downstream chunks come out byte-identical after an
+// edit.
+type rollingChunker struct {
+	window [rollingWindowSize]byte
+	pos    int
+	filled int
+	hash   uint64
+}
+
+// rollingBase and rollingHighBase implement the standard remove-oldest/
+// add-newest update for a base-rollingBase polynomial hash taken mod 2^64
+// (via uint64 wraparound): rollingHighBase == rollingBase^(rollingWindowSize-1) mod 2^64.
+const rollingBase uint64 = 1000000007
+
+var rollingHighBase = func() uint64 {
+	h := uint64(1)
+	for i := 0; i < rollingWindowSize-1; i++ {
+		h *= rollingBase
+	}
+	return h
+}()
+
+func newRollingChunker() *rollingChunker {
+	return &rollingChunker{}
+}
+
+// roll feeds one more byte of file content through the rolling hash.
+func (r *rollingChunker) roll(b byte) {
+	out := r.window[r.pos]
+	r.window[r.pos] = b
+	r.pos = (r.pos + 1) % rollingWindowSize
+
+	if r.filled < rollingWindowSize {
+		r.filled++
+		r.hash = r.hash*rollingBase + uint64(b)
+		return
+	}
+
+	r.hash = (r.hash-uint64(out)*rollingHighBase)*rollingBase + uint64(b)
+}
+
+// atBoundary reports whether the bytes most recently rolled through end on
+// a content-defined chunk boundary.
+func (r *rollingChunker) atBoundary() bool {
+	return r.filled == rollingWindowSize && r.hash&contentChunkBoundaryMask == 0
+}
@@ -0,0 +1,235 @@
+package modules
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRollingChunkerBoundaryIsContentDefined verifies that feeding the same
+// trailing rollingWindowSize bytes through two independent rollingChunkers
+// (regardless of what came before them) produces the same boundary
+// decision - the property content-defined chunking relies on to resync
+// after an edit.
+func TestRollingChunkerBoundaryIsContentDefined(t *testing.T) {
+	suffix := []byte("the quick brown fox jumps over the lazy dog, then naps in the sun 1234")
+
+	a := newRollingChunker()
+	for _, b := range []byte("some unrelated prefix that differs entirely") {
+		a.roll(b)
+	}
+	for _, b := range suffix {
+		a.roll(b)
+	}
+
+	b := newRollingChunker()
+	for _, c := range []byte("a completely different, shorter prefix") {
+		b.roll(c)
+	}
+	for _, c := range suffix {
+		b.roll(c)
+	}
+
+	if a.atBoundary() != b.atBoundary() {
+		t.Error("Expected the boundary decision to depend only on the trailing window, not on what preceded it")
+	}
+}
+
+// writeWordlistLines creates a wordlist file with n sequentially numbered
+// lines under a fixed prefix, used to build reproducible multi-chunk
+// fixtures.
+func writeWordlistLines(tb testing.TB, path string, prefix string, n int) {
+	tb.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		tb.Fatalf("Failed to create %s: %v", path, err)
+	}
+	defer f.Close()
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(f, "%s-password-%d\n", prefix, i)
+	}
+}
+
+// byteSplitChunkHashes reproduces the old fixed-size-only splitting rule
+// (cut whenever the current chunk would exceed chunkSize) so it can be
+// compared against the content-defined splitter below.
+func byteSplitChunkHashes(tb testing.TB, path string, chunkSize int64) []string {
+	tb.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		tb.Fatalf("Failed to read %s: %v", path, err)
+	}
+
+	var hashes []string
+	var chunk []byte
+	flush := func() {
+		if len(chunk) == 0 {
+			return
+		}
+		sum := sha256.Sum256(chunk)
+		hashes = append(hashes, hex.EncodeToString(sum[:]))
+		chunk = nil
+	}
+
+	start := 0
+	for start < len(data) {
+		end := start
+		for end < len(data) && data[end] != '\n' {
+			end++
+		}
+		if end < len(data) {
+			end++ // include the newline
+		}
+		line := data[start:end]
+		if int64(len(chunk)+len(line)) > chunkSize {
+			flush()
+		}
+		chunk = append(chunk, line...)
+		start = end
+	}
+	flush()
+
+	return hashes
+}
+
+// contentDefinedChunkHashes runs the real production chunker (buildChunks)
+// against path and returns the resulting chunk hashes.
+func contentDefinedChunkHashes(tb testing.TB, path string) []string {
+	tb.Helper()
+
+	cacheDir, err := os.MkdirTemp("", "brutespray-cdc-test-*")
+	if err != nil {
+		tb.Fatalf("Failed to create cache dir: %v", err)
+	}
+	defer os.RemoveAll(cacheDir)
+
+	cf := &ChunkedFile{OriginalPath: path, ChunkSize: FileChunkSize}
+	manifest, err := cf.buildChunks(cacheDir)
+	if err != nil {
+		tb.Fatalf("buildChunks failed: %v", err)
+	}
+	return manifest.SHA256
+}
+
+// dedupRatio reports what fraction of after's hashes are already present in
+// before.
+func dedupRatio(before, after []string) float64 {
+	seen := make(map[string]bool, len(before))
+	for _, h := range before {
+		seen[h] = true
+	}
+	reused := 0
+	for _, h := range after {
+		if seen[h] {
+			reused++
+		}
+	}
+	if len(after) == 0 {
+		return 0
+	}
+	return float64(reused) / float64(len(after))
+}
+
+// TestContentDefinedChunkingSurvivesAnEdit verifies the core promise of
+// this scheme: prepending one line to a multi-chunk wordlist leaves most
+// chunks byte-identical (and therefore reused from the blob store), while
+// the old fixed-size-only split reshuffles every chunk after the edit.
+func TestContentDefinedChunkingSurvivesAnEdit(t *testing.T) {
+	origMask, origMin := contentChunkBoundaryMask, minContentChunkSize
+	contentChunkBoundaryMask = (1 << 10) - 1 // a boundary about every 1K lines past the minimum, for a fast test
+	minContentChunkSize = 4 * 1024
+	defer func() {
+		contentChunkBoundaryMask = origMask
+		minContentChunkSize = origMin
+	}()
+
+	tmpDir, err := os.MkdirTemp("", "brutespray-cdc-edit-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	before := filepath.Join(tmpDir, "before.txt")
+	after := filepath.Join(tmpDir, "after.txt")
+	writeWordlistLines(t, before, "base", 20000)
+
+	// Build "after" as "before" with one line prepended.
+	orig, err := os.ReadFile(before)
+	if err != nil {
+		t.Fatalf("Failed to read before.txt: %v", err)
+	}
+	if err := os.WriteFile(after, append([]byte("prepended-line\n"), orig...), 0644); err != nil {
+		t.Fatalf("Failed to write after.txt: %v", err)
+	}
+
+	beforeCDC := contentDefinedChunkHashes(t, before)
+	afterCDC := contentDefinedChunkHashes(t, after)
+	if len(beforeCDC) < 3 {
+		t.Fatalf("Expected the fixture to produce multiple content-defined chunks, got %d", len(beforeCDC))
+	}
+
+	const byteSplitChunkSize = 1024
+	beforeByteSplit := byteSplitChunkHashes(t, before, byteSplitChunkSize)
+	afterByteSplit := byteSplitChunkHashes(t, after, byteSplitChunkSize)
+
+	cdcRatio := dedupRatio(beforeCDC, afterCDC)
+	byteRatio := dedupRatio(beforeByteSplit, afterByteSplit)
+
+	t.Logf("dedup ratio after prepending one line: content-defined=%.2f byte-split=%.2f", cdcRatio, byteRatio)
+
+	if cdcRatio < 0.8 {
+		t.Errorf("Expected content-defined chunking to reuse most chunks after a prepended line, got ratio %.2f", cdcRatio)
+	}
+	if byteRatio > 0.05 {
+		t.Errorf("Expected the byte-split scheme to reuse almost nothing after a prepended line, got ratio %.2f", byteRatio)
+	}
+}
+
+// BenchmarkChunkingDedupAfterEdit benchmarks both splitting schemes against
+// the same prepended-line edit and reports each one's dedup ratio as a
+// custom metric, so `go test -bench BenchmarkChunkingDedupAfterEdit -run=^$`
+// quantifies the improvement content-defined chunking makes over the old
+// fixed-size split.
+func BenchmarkChunkingDedupAfterEdit(b *testing.B) {
+	origMask, origMin := contentChunkBoundaryMask, minContentChunkSize
+	contentChunkBoundaryMask = (1 << 14) - 1 // a boundary about every 16K lines past the minimum
+	minContentChunkSize = 16 * 1024
+	defer func() {
+		contentChunkBoundaryMask = origMask
+		minContentChunkSize = origMin
+	}()
+
+	tmpDir, err := os.MkdirTemp("", "brutespray-cdc-bench-*")
+	if err != nil {
+		b.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	before := filepath.Join(tmpDir, "before.txt")
+	after := filepath.Join(tmpDir, "after.txt")
+	writeWordlistLines(b, before, "bench", 200000)
+
+	orig, err := os.ReadFile(before)
+	if err != nil {
+		b.Fatalf("Failed to read before.txt: %v", err)
+	}
+	if err := os.WriteFile(after, append([]byte("prepended-line\n"), orig...), 0644); err != nil {
+		b.Fatalf("Failed to write after.txt: %v", err)
+	}
+
+	const byteSplitChunkSize = 16 * 1024
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		beforeCDC := contentDefinedChunkHashes(b, before)
+		afterCDC := contentDefinedChunkHashes(b, after)
+		beforeByteSplit := byteSplitChunkHashes(b, before, byteSplitChunkSize)
+		afterByteSplit := byteSplitChunkHashes(b, after, byteSplitChunkSize)
+
+		b.ReportMetric(dedupRatio(beforeCDC, afterCDC), "content-defined-dedup-ratio")
+		b.ReportMetric(dedupRatio(beforeByteSplit, afterByteSplit), "byte-split-dedup-ratio")
+	}
+}
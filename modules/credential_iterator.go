@@ -3,6 +3,8 @@ package modules
 import (
 	"bufio"
 	"fmt"
+	"hash/fnv"
+	"io"
 	"os"
 	"strings"
 )
@@ -17,16 +19,26 @@ type CredentialIterator struct {
 	combo    string
 	version  string
 
-	// State for iteration
-	userFile     *os.File
-	passwordFile *os.File
-	userScanner  *bufio.Scanner
-	passScanner  *bufio.Scanner
-	comboFile    *os.File
-	comboScanner *bufio.Scanner
-
-	// Store original file paths for reopening if needed
+	// State for iteration. These are io.ReadCloser rather than *os.File
+	// because a user/password/combo spec may resolve to more than a plain
+	// file - a WordlistSource (see wordlist_source.go) covers http(s)://,
+	// stdin, and mem:// specs too - and because even a plain file may be
+	// gzip/zstd/bzip2 compressed (see openWordlist in
+	// wordlist_compression.go), in which case the stream backing the
+	// scanner is a decompressor wrapping the file, not the file itself.
+	userSource     io.ReadCloser
+	passwordSource io.ReadCloser
+	userScanner    *bufio.Scanner
+	passScanner    *bufio.Scanner
+	comboSource    io.ReadCloser
+	comboScanner   *bufio.Scanner
+
+	// Store the original user/password/combo spec (a path, or a
+	// WordlistSource spec like "http://..." or "stdin") for reopening a
+	// source if needed.
 	passwordFilePath string
+	userFilePath     string
+	comboFilePath    string
 
 	// Current values
 	currentUser     string
@@ -36,6 +48,12 @@ type CredentialIterator struct {
 	userIndex       int
 	passIndex       int
 
+	// Byte offsets consumed so far from each file-backed source, used to
+	// resume a scanner without replaying already-seen lines.
+	userFileOffset     int64
+	passwordFileOffset int64
+	comboFileOffset    int64
+
 	// Flags
 	isComboMode     bool
 	isPasswordOnly  bool // For VNC, SNMP
@@ -43,10 +61,57 @@ type CredentialIterator struct {
 	useDefaultPass  bool
 	done            bool
 	initialized     bool
+
+	// Checkpoint support (see checkpoint.go). checkpointPath is empty unless
+	// the iterator was created via NewResumableCredentialIterator.
+	checkpointPath     string
+	checkpointInterval int
+	sinceCheckpoint    int
+	resumeCheckpoint   *Checkpoint
+
+	// Shard selection, for splitting one target's credential space across
+	// cooperating brutespray instances (see inShard/comboLineInShard).
+	// shardCount <= 1 means sharding is disabled and every credential is
+	// kept.
+	shardIndex     int
+	shardCount     int
+	comboLineIndex int64
+
+	// Attempt journal (see credential_journal.go) and the 0-based ordinals
+	// it records. Unlike userIndex/passIndex above - which track how far
+	// each underlying source has been read, offset by the scanner's
+	// read-ahead - userOrdinal/passOrdinal/lastComboOrdinal always describe
+	// the pair just yielded by Next() in this iterator's deterministic
+	// ordering, so SeekTo can reposition either backend from them alone.
+	journal          *CredentialJournal
+	userOrdinal      int64
+	passOrdinal      int64
+	lastComboOrdinal int64
 }
 
-// NewCredentialIterator creates a new credential iterator
-func NewCredentialIterator(host *Host, user, password, combo, version string, isPasswordOnly bool) (*CredentialIterator, error) {
+// NewCredentialIterator creates a new credential iterator. shardCount <= 1
+// disables sharding (shardIndex is then ignored); otherwise Next only yields
+// the shardIndex-th slice of shardCount, so running one iterator per
+// shardIndex from 0 to shardCount-1 covers the full credential space exactly
+// once each, with no overlap between shards.
+//
+// Deterministic ordering contract: for a given (user, password, combo)
+// configuration, two iterators built from the same, unmodified sources
+// always yield credentials in the exact same order - users (then passwords
+// within each user) in file/slice order for standard and password-only
+// mode, combo lines in file order for combo mode. SeekTo and the attempt
+// journal (see NewJournaledCredentialIterator) both depend on this: a
+// (userIdx, passIdx) position recorded against one run only identifies the
+// same credential in a later run if the underlying wordlists haven't
+// changed in the meantime.
+func NewCredentialIterator(host *Host, user, password, combo, version string, isPasswordOnly bool, shardIndex, shardCount int) (*CredentialIterator, error) {
+	if shardCount <= 0 {
+		shardCount = 1
+	}
+	if shardIndex < 0 || shardIndex >= shardCount {
+		return nil, fmt.Errorf("invalid shard %d/%d: shardIndex must be in [0, %d)", shardIndex, shardCount, shardCount)
+	}
+
 	iter := &CredentialIterator{
 		host:           host,
 		user:           user,
@@ -55,11 +120,235 @@ func NewCredentialIterator(host *Host, user, password, combo, version string, is
 		version:        version,
 		isPasswordOnly: isPasswordOnly,
 		isComboMode:    combo != "",
+		shardIndex:     shardIndex,
+		shardCount:     shardCount,
+	}
+
+	return iter, nil
+}
+
+// NewResumableCredentialIterator creates a credential iterator that persists
+// its progress to checkpointPath as it goes, and resumes from it on the next
+// call against the same target if the checkpoint is still present. Pass a
+// checkpointPath to enable the behavior; checkpointInterval controls how
+// many credentials are yielded between writes (DefaultCheckpointInterval if
+// <= 0).
+func NewResumableCredentialIterator(host *Host, user, password, combo, version string, isPasswordOnly bool, shardIndex, shardCount int, checkpointPath string, checkpointInterval int) (*CredentialIterator, error) {
+	iter, err := NewCredentialIterator(host, user, password, combo, version, isPasswordOnly, shardIndex, shardCount)
+	if err != nil {
+		return nil, err
+	}
+
+	if checkpointInterval <= 0 {
+		checkpointInterval = DefaultCheckpointInterval
+	}
+
+	iter.checkpointPath = checkpointPath
+	iter.checkpointInterval = checkpointInterval
+
+	return iter, nil
+}
+
+// NewJournaledCredentialIterator creates a credential iterator that appends
+// an attempt record to journalPath after every credential it yields (see
+// CredentialJournal). If resume is true and the journal already has a
+// record for this host:port:service, the iterator seeks straight past
+// every already-attempted pair via SeekTo instead of starting over - so a
+// run that died partway through a large wordlist only has to redo work
+// back to its last unsynced batch, not from the beginning. The CLI layer is
+// expected to expose this as a --resume <journal> flag that passes the
+// same path the original run was journaling to.
+func NewJournaledCredentialIterator(host *Host, user, password, combo, version string, isPasswordOnly bool, shardIndex, shardCount int, journalPath string, resume bool) (*CredentialIterator, error) {
+	iter, err := NewCredentialIterator(host, user, password, combo, version, isPasswordOnly, shardIndex, shardCount)
+	if err != nil {
+		return nil, err
+	}
+
+	journal, err := OpenCredentialJournal(journalPath)
+	if err != nil {
+		return nil, err
+	}
+	iter.journal = journal
+
+	if resume {
+		userIdx, passIdx, found, err := LatestJournalCursor(journalPath, host.Host, host.Port, host.Service)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[WARNING] Ignoring unusable journal %s: %v\n", journalPath, err)
+		} else if found {
+			fmt.Fprintf(os.Stderr, "[INFO] Resuming %s:%d from journal %s\n", host.Host, host.Port, journalPath)
+			// The recorded cursor was already attempted; resume one past it.
+			// If that rolls past the end of the current user's passwords,
+			// SeekTo/nextStandard's normal end-of-user handling advances to
+			// the next user automatically.
+			//
+			// Combo mode needs the "+1" applied to userIdx instead of passIdx:
+			// LatestJournalCursor returns userIdx as lastComboOrdinal, the
+			// 0-based line index already attempted, and SeekTo's combo branch
+			// passes userIdx to skipComboLines as the number of lines to
+			// skip, so skipping past the attempted line takes userIdx+1.
+			var seekErr error
+			if iter.isComboMode {
+				seekErr = iter.SeekTo(userIdx+1, 0)
+			} else {
+				seekErr = iter.SeekTo(userIdx, passIdx+1)
+			}
+			if seekErr != nil {
+				iter.Close()
+				return nil, fmt.Errorf("error resuming from journal: %w", seekErr)
+			}
+		}
 	}
 
 	return iter, nil
 }
 
+// inShard reports whether the user:password pair belongs to this iterator's
+// shard. Used for every mode except combo, which shards by line number
+// instead (see comboLineInShard) to keep ordering deterministic even if the
+// hash function ever changes.
+func (ci *CredentialIterator) inShard(user, password string) bool {
+	if ci.shardCount <= 1 {
+		return true
+	}
+	h := fnv.New64a()
+	h.Write([]byte(user))
+	h.Write([]byte(":"))
+	h.Write([]byte(password))
+	return int(h.Sum64()%uint64(ci.shardCount)) == ci.shardIndex
+}
+
+// comboLineInShard reports whether the combo line currently being read
+// belongs to this iterator's shard, sharding by line number (rather than
+// hashing the line's contents) so resuming mid-file only has to track a
+// single counter.
+func (ci *CredentialIterator) comboLineInShard() bool {
+	idx := ci.comboLineIndex
+	ci.comboLineIndex++
+	ci.lastComboOrdinal = idx
+	if ci.shardCount <= 1 {
+		return true
+	}
+	return idx%int64(ci.shardCount) == int64(ci.shardIndex)
+}
+
+// checkpointMode returns the mode string recorded in / compared against a
+// checkpoint file for this iterator's configuration.
+func (ci *CredentialIterator) checkpointMode() string {
+	if ci.isComboMode {
+		return checkpointModeCombo
+	}
+	if ci.isPasswordOnly {
+		return checkpointModePasswordOnly
+	}
+	return checkpointModeStandard
+}
+
+// loadResumeCheckpoint reads the checkpoint file (if any) and validates it
+// matches this iterator's target and mode before accepting it. A mismatched
+// or unreadable checkpoint is logged and ignored rather than treated as
+// fatal, since restarting from scratch is always safe.
+func (ci *CredentialIterator) loadResumeCheckpoint() {
+	if ci.checkpointPath == "" {
+		return
+	}
+
+	cp, err := LoadCheckpoint(ci.checkpointPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[WARNING] Ignoring unusable checkpoint %s: %v\n", ci.checkpointPath, err)
+		return
+	}
+	if cp == nil {
+		return
+	}
+
+	if cp.Host != ci.host.Host || cp.Port != ci.host.Port || cp.Service != ci.host.Service || cp.Mode != ci.checkpointMode() {
+		fmt.Fprintf(os.Stderr, "[WARNING] Checkpoint %s does not match current target/mode, ignoring\n", ci.checkpointPath)
+		return
+	}
+	if cp.ShardIndex != ci.shardIndex || cp.ShardCount != ci.shardCount {
+		fmt.Fprintf(os.Stderr, "[WARNING] Checkpoint %s was recorded for shard %d/%d, not %d/%d; ignoring\n", ci.checkpointPath, cp.ShardIndex, cp.ShardCount, ci.shardIndex, ci.shardCount)
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "[INFO] Resuming %s:%d from checkpoint %s\n", ci.host.Host, ci.host.Port, ci.checkpointPath)
+	ci.resumeCheckpoint = cp
+}
+
+// buildCheckpoint snapshots the iterator's current cursor state.
+func (ci *CredentialIterator) buildCheckpoint() *Checkpoint {
+	return &Checkpoint{
+		SchemaVersion:      CheckpointSchemaVersion,
+		Host:               ci.host.Host,
+		Port:               ci.host.Port,
+		Service:            ci.host.Service,
+		Mode:               ci.checkpointMode(),
+		UserFileOffset:     ci.userFileOffset,
+		PasswordFileOffset: ci.passwordFileOffset,
+		ComboFileOffset:    ci.comboFileOffset,
+		UserIndex:          ci.userIndex,
+		PassIndex:          ci.passIndex,
+		CurrentUser:        ci.currentUser,
+		ShardIndex:         ci.shardIndex,
+		ShardCount:         ci.shardCount,
+		ComboLineIndex:     ci.comboLineIndex,
+	}
+}
+
+// saveCheckpointNow writes the current cursor state immediately, ignoring
+// (but logging) a failure since losing a checkpoint write is not worth
+// aborting the run over.
+func (ci *CredentialIterator) saveCheckpointNow() {
+	if ci.checkpointPath == "" {
+		return
+	}
+	if err := ci.buildCheckpoint().Save(ci.checkpointPath); err != nil {
+		fmt.Fprintf(os.Stderr, "[WARNING] Failed to write checkpoint: %v\n", err)
+	}
+}
+
+// maybeCheckpoint is called after each credential is yielded and writes the
+// checkpoint every checkpointInterval credentials.
+func (ci *CredentialIterator) maybeCheckpoint() {
+	if ci.checkpointPath == "" {
+		return
+	}
+	ci.sinceCheckpoint++
+	if ci.sinceCheckpoint >= ci.checkpointInterval {
+		ci.sinceCheckpoint = 0
+		ci.saveCheckpointNow()
+	}
+}
+
+// openSourceAt resolves spec via the WordlistSource abstraction (plain file,
+// http(s)://, stdin, mem://) and opens it, seeking to offset first when the
+// underlying reader supports it. An HTTP(S) source is read from its on-disk
+// download cache, so it seeks like a plain file. Compressed sources and
+// non-seekable ones alike (stdin, mem://) simply can't honor a non-zero offset; a
+// failed or unsupported seek is logged and ignored rather than treated as
+// fatal, and the affected source restarts from the beginning.
+func openSourceAt(spec string, offset int64) (io.ReadCloser, *bufio.Scanner, error) {
+	source, err := ResolveWordlistSource(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reader, err := source.Open()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening %s: %w", source.Name(), err)
+	}
+
+	if offset > 0 {
+		seeker, ok := reader.(io.Seeker)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "[WARNING] %s cannot be seeked to checkpointed offset %d, restarting it from the beginning\n", source.Name(), offset)
+		} else if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+			fmt.Fprintf(os.Stderr, "[WARNING] Could not seek %s to checkpointed offset %d, restarting it from the beginning: %v\n", source.Name(), offset, err)
+		}
+	}
+
+	return reader, bufferedLineScanner(reader), nil
+}
+
 // initialize sets up the iterator on first use
 func (ci *CredentialIterator) initialize() error {
 	if ci.initialized {
@@ -69,6 +358,17 @@ func (ci *CredentialIterator) initialize() error {
 	fmt.Fprintf(os.Stderr, "[DEBUG] Initializing credential iterator for %s:%d\n", ci.host.Host, ci.host.Port)
 
 	ci.initialized = true
+	ci.userOrdinal = -1
+	ci.passOrdinal = -1
+	ci.loadResumeCheckpoint()
+
+	if !ci.isComboMode && !ci.isPasswordOnly && isStdinSpec(ci.user) && isStdinSpec(ci.password) {
+		// Opening both as WordlistSources would race two reads over the same
+		// os.Stdin - there's no sane way to split one stream into a user list
+		// and a password list, so refuse rather than silently yielding nothing
+		// (see the matching guard in GetUsersAndPasswords).
+		return fmt.Errorf("user and password cannot both be read from stdin")
+	}
 
 	if ci.isComboMode {
 		return ci.initializeCombo()
@@ -82,42 +382,93 @@ func (ci *CredentialIterator) initialize() error {
 	} else {
 		// Initialize users
 		if ci.user != "" {
-			if IsFile(ci.user) {
-				fmt.Fprintf(os.Stderr, "[DEBUG] Opening user file: %s\n", ci.user)
-				file, err := os.Open(ci.user)
+			if IsWordlistSourceSpec(ci.user) {
+				fmt.Fprintf(os.Stderr, "[DEBUG] Opening user source: %s\n", ci.user)
+				offset := int64(0)
+				if ci.resumeCheckpoint != nil {
+					offset = ci.resumeCheckpoint.UserFileOffset
+				}
+				source, scanner, err := openSourceAt(ci.user, offset)
 				if err != nil {
-					return fmt.Errorf("error opening user file: %w", err)
+					return fmt.Errorf("error opening user source: %w", err)
 				}
-				ci.userFile = file
-				ci.userScanner = bufio.NewScanner(file)
-				ci.userScanner.Buffer(make([]byte, 64*1024), 1024*1024) // 64KB buffer, 1MB max line length
-				fmt.Fprintf(os.Stderr, "[DEBUG] User file opened successfully\n")
+				ci.userSource = source
+				ci.userFilePath = ci.user
+				ci.userFileOffset = offset
+				ci.userScanner = scanner
+				fmt.Fprintf(os.Stderr, "[DEBUG] User source opened successfully\n")
 			} else {
 				ci.users = []string{ci.user}
+				if ci.resumeCheckpoint != nil {
+					ci.userIndex = ci.resumeCheckpoint.UserIndex
+				}
 			}
 		} else {
 			// Use default wordlist
 			ci.useDefaultUsers = true
 			ci.users = GetUsersFromDefaultWordlist(ci.version, ci.host.Service)
+			if ci.resumeCheckpoint != nil {
+				ci.userIndex = ci.resumeCheckpoint.UserIndex
+			}
 		}
 	}
 
 	// Initialize passwords
 	if ci.password != "" {
-		if IsFile(ci.password) {
-			fmt.Fprintf(os.Stderr, "[DEBUG] Opening password file: %s\n", ci.password)
-			file, err := os.Open(ci.password)
+		if IsWordlistSourceSpec(ci.password) {
+			resolved, err := ResolveWordlistSource(ci.password)
 			if err != nil {
-				ci.Close() // Clean up user file if opened
-				return fmt.Errorf("error opening password file: %w", err)
+				ci.Close() // Clean up user source if opened
+				return fmt.Errorf("error resolving password source: %w", err)
+			}
+
+			if _, ok := resolved.(StdinSource); ok {
+				// standard mode resets the password cursor back to the start
+				// for every user (see resetPasswords), which stdin can't
+				// honor - it can only be read once. Buffer it into a slice
+				// instead of streaming it, the same as a literal value or
+				// the default wordlist, so every user gets the full list.
+				fmt.Fprintf(os.Stderr, "[DEBUG] Buffering password source into memory: %s\n", ci.password)
+				passwords, err := readWordlistSourceLines(ci.password)
+				if err != nil {
+					ci.Close()
+					return fmt.Errorf("error reading password source: %w", err)
+				}
+				ci.passwords = passwords
+				if ci.resumeCheckpoint != nil {
+					ci.passIndex = ci.resumeCheckpoint.PassIndex
+				}
+			} else {
+				fmt.Fprintf(os.Stderr, "[DEBUG] Opening password source: %s\n", ci.password)
+				offset := int64(0)
+				if ci.resumeCheckpoint != nil {
+					offset = ci.resumeCheckpoint.PasswordFileOffset
+				}
+				source, scanner, err := openSourceAt(ci.password, offset)
+				if err != nil {
+					ci.Close() // Clean up user source if opened
+					return fmt.Errorf("error opening password source: %w", err)
+				}
+				ci.passwordSource = source
+				if httpSource, ok := resolved.(HTTPSource); ok {
+					// By this point ensureDownloaded has already run (inside
+					// Open above), so the cache file is on disk; reopen
+					// directly from there on every per-user reset instead of
+					// re-resolving the URL, which would otherwise repeat a
+					// Range request against the origin for every single user.
+					ci.passwordFilePath = httpSource.cachePath()
+				} else {
+					ci.passwordFilePath = ci.password // Store spec for reopening in resetPasswords
+				}
+				ci.passwordFileOffset = offset
+				ci.passScanner = scanner
+				fmt.Fprintf(os.Stderr, "[DEBUG] Password source opened successfully\n")
 			}
-			ci.passwordFile = file
-			ci.passwordFilePath = ci.password // Store path for potential reopening
-			ci.passScanner = bufio.NewScanner(file)
-			ci.passScanner.Buffer(make([]byte, 64*1024), 1024*1024) // 64KB buffer, 1MB max line length
-			fmt.Fprintf(os.Stderr, "[DEBUG] Password file opened successfully\n")
 		} else {
 			ci.passwords = []string{ci.password}
+			if ci.resumeCheckpoint != nil {
+				ci.passIndex = ci.resumeCheckpoint.PassIndex
+			}
 		}
 	} else {
 		if UseEmptyPassword {
@@ -127,24 +478,43 @@ func (ci *CredentialIterator) initialize() error {
 			// Use default wordlist
 			ci.useDefaultPass = true
 			ci.passwords = GetPasswordsFromDefaultWordlist(ci.version, ci.host.Service)
+			if ci.resumeCheckpoint != nil {
+				ci.passIndex = ci.resumeCheckpoint.PassIndex
+			}
 		}
 	}
 
+	// Resume mid-user: the user file/slice cursor above already points past
+	// this user (it advances as soon as the user is read), so nextStandard
+	// must be told to keep going with this user's passwords rather than
+	// advancing to the next one.
+	if ci.resumeCheckpoint != nil && !ci.isPasswordOnly {
+		ci.currentUser = ci.resumeCheckpoint.CurrentUser
+	}
+
 	return nil
 }
 
 // initializeCombo sets up combo mode iteration
 func (ci *CredentialIterator) initializeCombo() error {
-	if IsFile(ci.combo) {
-		fmt.Fprintf(os.Stderr, "[DEBUG] Opening combo file: %s\n", ci.combo)
-		file, err := os.Open(ci.combo)
+	if IsWordlistSourceSpec(ci.combo) {
+		fmt.Fprintf(os.Stderr, "[DEBUG] Opening combo source: %s\n", ci.combo)
+		offset := int64(0)
+		if ci.resumeCheckpoint != nil {
+			offset = ci.resumeCheckpoint.ComboFileOffset
+		}
+		source, scanner, err := openSourceAt(ci.combo, offset)
 		if err != nil {
-			return fmt.Errorf("error opening combo file: %w", err)
+			return fmt.Errorf("error opening combo source: %w", err)
+		}
+		ci.comboSource = source
+		ci.comboFilePath = ci.combo
+		ci.comboFileOffset = offset
+		ci.comboScanner = scanner
+		if ci.resumeCheckpoint != nil {
+			ci.comboLineIndex = ci.resumeCheckpoint.ComboLineIndex
 		}
-		ci.comboFile = file
-		ci.comboScanner = bufio.NewScanner(file)
-		ci.comboScanner.Buffer(make([]byte, 64*1024), 1024*1024) // 64KB buffer, 1MB max line length
-		fmt.Fprintf(os.Stderr, "[DEBUG] Combo file opened successfully\n")
+		fmt.Fprintf(os.Stderr, "[DEBUG] Combo source opened successfully\n")
 	} else {
 		// Single combo value
 		splits := strings.SplitN(ci.combo, ":", 2)
@@ -153,6 +523,9 @@ func (ci *CredentialIterator) initializeCombo() error {
 		}
 		ci.users = []string{splits[0]}
 		ci.passwords = []string{splits[1]}
+		if ci.resumeCheckpoint != nil {
+			ci.userIndex = ci.resumeCheckpoint.UserIndex
+		}
 	}
 	return nil
 }
@@ -171,17 +544,192 @@ func (ci *CredentialIterator) Next() (user, password string, ok bool) {
 		return "", "", false
 	}
 
-	if ci.isComboMode {
-		return ci.nextCombo()
+	// Sharding for combo mode is by line number and handled inside nextCombo
+	// itself (it already loops to skip malformed lines); standard and
+	// password-only modes shard by hashing the candidate here instead, since
+	// skipping one just means asking the underlying iterator for another.
+	for {
+		var u, p string
+		if ci.isComboMode {
+			u, p, ok = ci.nextCombo()
+		} else if ci.isPasswordOnly {
+			// For password-only services (VNC, SNMP), iterate only passwords
+			u, p, ok = ci.nextPasswordOnly()
+		} else {
+			// Standard mode: iterate all user/password combinations
+			u, p, ok = ci.nextStandard()
+		}
+
+		if !ok {
+			return "", "", false
+		}
+
+		if !ci.isComboMode && !ci.inShard(u, p) {
+			continue
+		}
+
+		ci.maybeCheckpoint()
+		ci.recordAttempt()
+		return u, p, true
 	}
+}
 
-	// For password-only services (VNC, SNMP), iterate only passwords
-	if ci.isPasswordOnly {
-		return ci.nextPasswordOnly()
+// currentCursor returns the 0-based (userIdx, passIdx) position, in this
+// iterator's deterministic ordering, of the credential just yielded by
+// Next(). passwordOnly mode has no user dimension (userIdx is always 0);
+// combo mode has no independent password dimension (passIdx is always 0,
+// and userIdx is the combo line's ordinal).
+func (ci *CredentialIterator) currentCursor() (userIdx, passIdx int64) {
+	switch {
+	case ci.isComboMode:
+		return ci.lastComboOrdinal, 0
+	case ci.isPasswordOnly:
+		return 0, ci.passOrdinal
+	default:
+		return ci.userOrdinal, ci.passOrdinal
+	}
+}
+
+// recordAttempt appends this credential's cursor to the iterator's journal
+// (see credential_journal.go), if one was attached via
+// NewJournaledCredentialIterator. Unlike checkpointing, this runs after
+// every credential rather than every checkpointInterval - the journal is
+// what lets a resumed run skip exactly the pairs already tried instead of
+// replaying back to the last checkpoint.
+func (ci *CredentialIterator) recordAttempt() {
+	if ci.journal == nil {
+		return
+	}
+	userIdx, passIdx := ci.currentCursor()
+	if err := ci.journal.Record(ci.host.Host, ci.host.Port, ci.host.Service, userIdx, passIdx, JournalStatusAttempted); err != nil {
+		fmt.Fprintf(os.Stderr, "[WARNING] Failed to write journal record: %v\n", err)
+	}
+}
+
+// SeekTo fast-forwards the iterator so the next call to Next() yields the
+// credential at (userIdx, passIdx) in this iterator's deterministic
+// ordering (see NewCredentialIterator), without materializing - copying out
+// via Scan()'s Text() - any of the credentials skipped over. It works
+// identically whether users/passwords/combo are backed by an in-memory
+// slice (a literal value or the default wordlist) or by a file: a
+// slice-backed cursor is advanced directly, while a file-backed one is
+// advanced by calling the scanner's Scan() the requested number of times
+// and reading its length via Bytes() rather than Text(), so no skipped
+// line is ever copied into a string.
+//
+// SeekTo must be called (if at all) before the first call to Next().
+// passIdx is ignored in combo mode, which has no independent password
+// dimension; userIdx is ignored in password-only mode, which has no user
+// dimension.
+func (ci *CredentialIterator) SeekTo(userIdx, passIdx int64) error {
+	if !ci.initialized {
+		if err := ci.initialize(); err != nil {
+			return err
+		}
+	}
+	if ci.done {
+		return nil
 	}
 
-	// Standard mode: iterate all user/password combinations
-	return ci.nextStandard()
+	switch {
+	case ci.isComboMode:
+		return ci.skipComboLines(userIdx)
+	case ci.isPasswordOnly:
+		return ci.skipPasswords(passIdx)
+	default:
+		if err := ci.skipUsers(userIdx); err != nil {
+			return err
+		}
+		return ci.skipPasswords(passIdx)
+	}
+}
+
+// skipUsers discards the next n users without materializing them, for
+// whichever backend (scanner or slice) this iterator's users are stored in.
+func (ci *CredentialIterator) skipUsers(n int64) error {
+	if n <= 0 {
+		return nil
+	}
+
+	if ci.userScanner != nil {
+		var skipped int64
+		for skipped < n && ci.userScanner.Scan() {
+			ci.userFileOffset += int64(len(ci.userScanner.Bytes())) + 1
+			skipped++
+		}
+		if err := ci.userScanner.Err(); err != nil {
+			return fmt.Errorf("error seeking user file: %w", err)
+		}
+		ci.userOrdinal += skipped
+		return nil
+	}
+
+	skip := n
+	if remaining := int64(len(ci.users) - ci.userIndex); skip > remaining {
+		skip = remaining
+	}
+	ci.userIndex += int(skip)
+	ci.userOrdinal += skip
+	return nil
+}
+
+// skipPasswords discards the next n passwords without materializing them,
+// for whichever backend this iterator's passwords are stored in.
+func (ci *CredentialIterator) skipPasswords(n int64) error {
+	if n <= 0 {
+		return nil
+	}
+
+	if ci.passScanner != nil {
+		var skipped int64
+		for skipped < n && ci.passScanner.Scan() {
+			ci.passwordFileOffset += int64(len(ci.passScanner.Bytes())) + 1
+			skipped++
+		}
+		if err := ci.passScanner.Err(); err != nil {
+			return fmt.Errorf("error seeking password file: %w", err)
+		}
+		ci.passOrdinal += skipped
+		return nil
+	}
+
+	skip := n
+	if remaining := int64(len(ci.passwords) - ci.passIndex); skip > remaining {
+		skip = remaining
+	}
+	ci.passIndex += int(skip)
+	ci.passOrdinal += skip
+	return nil
+}
+
+// skipComboLines discards the next n combo lines without materializing
+// them, advancing comboLineIndex (and hence comboLineInShard's modulo
+// position) the same as if those lines had been read normally.
+func (ci *CredentialIterator) skipComboLines(n int64) error {
+	if n <= 0 {
+		return nil
+	}
+
+	if ci.comboScanner != nil {
+		var skipped int64
+		for skipped < n && ci.comboScanner.Scan() {
+			ci.comboFileOffset += int64(len(ci.comboScanner.Bytes())) + 1
+			skipped++
+		}
+		if err := ci.comboScanner.Err(); err != nil {
+			return fmt.Errorf("error seeking combo file: %w", err)
+		}
+		ci.comboLineIndex += skipped
+		return nil
+	}
+
+	skip := n
+	if remaining := int64(len(ci.users) - ci.userIndex); skip > remaining {
+		skip = remaining
+	}
+	ci.userIndex += int(skip)
+	ci.comboLineIndex += skip
+	return nil
 }
 
 // nextCombo returns next credential in combo mode
@@ -190,8 +738,12 @@ func (ci *CredentialIterator) nextCombo() (user, password string, ok bool) {
 		// Reading from file
 		for ci.comboScanner.Scan() {
 			line := ci.comboScanner.Text()
+			ci.comboFileOffset += int64(len(line)) + 1
 			splits := strings.SplitN(line, ":", 2)
 			if len(splits) == 2 {
+				if !ci.comboLineInShard() {
+					continue
+				}
 				return splits[0], splits[1], true
 			} else {
 				// Skip invalid lines with a warning instead of terminating
@@ -209,10 +761,13 @@ func (ci *CredentialIterator) nextCombo() (user, password string, ok bool) {
 	}
 
 	// Single combo value
-	if ci.userIndex < len(ci.users) {
+	for ci.userIndex < len(ci.users) {
 		user := ci.users[ci.userIndex]
 		pass := ci.passwords[ci.userIndex]
 		ci.userIndex++
+		if !ci.comboLineInShard() {
+			continue
+		}
 		return user, pass, true
 	}
 
@@ -225,7 +780,10 @@ func (ci *CredentialIterator) nextPasswordOnly() (user, password string, ok bool
 	if ci.passScanner != nil {
 		// Reading from file
 		if ci.passScanner.Scan() {
-			return "", ci.passScanner.Text(), true
+			line := ci.passScanner.Text()
+			ci.passwordFileOffset += int64(len(line)) + 1
+			ci.passOrdinal++
+			return "", line, true
 		}
 		if err := ci.passScanner.Err(); err != nil {
 			fmt.Fprintf(os.Stderr, "[ERROR] Error reading password file: %v\n", err)
@@ -240,6 +798,7 @@ func (ci *CredentialIterator) nextPasswordOnly() (user, password string, ok bool
 	if ci.passIndex < len(ci.passwords) {
 		pass := ci.passwords[ci.passIndex]
 		ci.passIndex++
+		ci.passOrdinal++
 		return "", pass, true
 	}
 
@@ -273,6 +832,13 @@ func (ci *CredentialIterator) nextStandard() (user, password string, ok bool) {
 
 	// Get first password for new user
 	if ci.nextPassword() {
+		// currentUser and the password cursor now both reflect this
+		// credential having been yielded, so persist immediately rather
+		// than waiting for the next checkpoint interval - otherwise a
+		// crash right after this credential is returned would resume by
+		// replaying it, and everything tried for the user we just
+		// finished, from scratch.
+		ci.saveCheckpointNow()
 		return ci.currentUser, ci.currentPassword, true
 	}
 
@@ -287,6 +853,8 @@ func (ci *CredentialIterator) nextUser() bool {
 		// Reading from file
 		if ci.userScanner.Scan() {
 			ci.currentUser = ci.userScanner.Text()
+			ci.userFileOffset += int64(len(ci.currentUser)) + 1
+			ci.userOrdinal++
 			return true
 		}
 		if err := ci.userScanner.Err(); err != nil {
@@ -301,6 +869,7 @@ func (ci *CredentialIterator) nextUser() bool {
 	if ci.userIndex < len(ci.users) {
 		ci.currentUser = ci.users[ci.userIndex]
 		ci.userIndex++
+		ci.userOrdinal++
 		return true
 	}
 
@@ -313,6 +882,8 @@ func (ci *CredentialIterator) nextPassword() bool {
 		// Reading from file
 		if ci.passScanner.Scan() {
 			ci.currentPassword = ci.passScanner.Text()
+			ci.passwordFileOffset += int64(len(ci.currentPassword)) + 1
+			ci.passOrdinal++
 			return true
 		}
 		if err := ci.passScanner.Err(); err != nil {
@@ -327,6 +898,7 @@ func (ci *CredentialIterator) nextPassword() bool {
 	if ci.passIndex < len(ci.passwords) {
 		ci.currentPassword = ci.passwords[ci.passIndex]
 		ci.passIndex++
+		ci.passOrdinal++
 		return true
 	}
 
@@ -336,60 +908,70 @@ func (ci *CredentialIterator) nextPassword() bool {
 // resetPasswords resets password iteration to start
 func (ci *CredentialIterator) resetPasswords() {
 	if ci.passScanner != nil {
-		// For file-based passwords, seek back to beginning if possible
-		if ci.passwordFile != nil {
-			_, err := ci.passwordFile.Seek(0, 0)
+		// A compressed source can't Seek(0, 0), and a mem:// source isn't
+		// seekable at all, so always reopen-and-rewrap from the stored spec
+		// rather than trying to seek the existing one. (A stdin password
+		// list never gets here: initialize buffers it into ci.passwords.)
+		if ci.passwordSource != nil {
+			ci.passwordSource.Close()
+			source, scanner, err := openSourceAt(ci.passwordFilePath, 0)
 			if err != nil {
-				// If seek fails and we have the file path, try to reopen
-				if ci.passwordFilePath != "" {
-					ci.passwordFile.Close()
-					file, err := os.Open(ci.passwordFilePath)
-					if err != nil {
-						fmt.Fprintf(os.Stderr, "Error reopening password file: %v\n", err)
-						ci.done = true
-						return
-					}
-					ci.passwordFile = file
-				} else {
-					// No file path stored, can't reopen
-					fmt.Fprintf(os.Stderr, "Error seeking password file and no path to reopen: %v\n", err)
-					ci.done = true
-					return
-				}
+				fmt.Fprintf(os.Stderr, "Error reopening password source: %v\n", err)
+				ci.done = true
+				return
 			}
-			ci.passScanner = bufio.NewScanner(ci.passwordFile)
-			ci.passScanner.Buffer(make([]byte, 64*1024), 1024*1024) // 64KB buffer, 1MB max line length
+			ci.passwordSource = source
+			ci.passScanner = scanner
 		}
+		ci.passwordFileOffset = 0
 	} else {
 		// Just reset index for slice-based passwords
 		ci.passIndex = 0
 	}
+	ci.passOrdinal = -1
 	ci.currentPassword = ""
 }
 
 // Close cleans up file handles
 func (ci *CredentialIterator) Close() error {
+	if ci.checkpointPath != "" {
+		if ci.done {
+			if err := DeleteCheckpoint(ci.checkpointPath); err != nil {
+				fmt.Fprintf(os.Stderr, "[WARNING] Failed to remove checkpoint: %v\n", err)
+			}
+		} else {
+			ci.saveCheckpointNow()
+		}
+	}
+
 	var errors []error
 
-	if ci.userFile != nil {
-		if err := ci.userFile.Close(); err != nil {
+	if ci.userSource != nil {
+		if err := ci.userSource.Close(); err != nil {
 			errors = append(errors, fmt.Errorf("error closing user file: %w", err))
 		}
-		ci.userFile = nil
+		ci.userSource = nil
 	}
 
-	if ci.passwordFile != nil {
-		if err := ci.passwordFile.Close(); err != nil {
+	if ci.passwordSource != nil {
+		if err := ci.passwordSource.Close(); err != nil {
 			errors = append(errors, fmt.Errorf("error closing password file: %w", err))
 		}
-		ci.passwordFile = nil
+		ci.passwordSource = nil
 	}
 
-	if ci.comboFile != nil {
-		if err := ci.comboFile.Close(); err != nil {
+	if ci.comboSource != nil {
+		if err := ci.comboSource.Close(); err != nil {
 			errors = append(errors, fmt.Errorf("error closing combo file: %w", err))
 		}
-		ci.comboFile = nil
+		ci.comboSource = nil
+	}
+
+	if ci.journal != nil {
+		if err := ci.journal.Close(); err != nil {
+			errors = append(errors, fmt.Errorf("error closing credential journal: %w", err))
+		}
+		ci.journal = nil
 	}
 
 	if len(errors) > 0 {
@@ -0,0 +1,277 @@
+package modules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func drainCredentials(t *testing.T, iter *CredentialIterator) []string {
+	t.Helper()
+	var got []string
+	for {
+		u, p, ok := iter.Next()
+		if !ok {
+			break
+		}
+		got = append(got, fmt.Sprintf("%s:%s", u, p))
+	}
+	return got
+}
+
+// TestSeekToStandardModeSlices verifies SeekTo lands on the exact
+// (userIdx, passIdx) pair for slice-backed (literal-value) users/passwords.
+func TestSeekToStandardModeSlices(t *testing.T) {
+	host := &Host{Host: "127.0.0.1", Port: 22, Service: "ssh"}
+
+	iter, err := NewCredentialIterator(host, "alice", "hunter2", "", "1.0", false, 0, 1)
+	if err != nil {
+		t.Fatalf("NewCredentialIterator failed: %v", err)
+	}
+	defer iter.Close()
+
+	if err := iter.SeekTo(0, 0); err != nil {
+		t.Fatalf("SeekTo failed: %v", err)
+	}
+
+	u, p, ok := iter.Next()
+	if !ok || u != "alice" || p != "hunter2" {
+		t.Errorf("Expected (alice, hunter2), got (%s, %s, ok=%v)", u, p, ok)
+	}
+}
+
+// TestSeekToStandardModeFiles verifies SeekTo fast-forwards file-backed
+// users/passwords to the requested position, skipping every earlier pair.
+func TestSeekToStandardModeFiles(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "brutespray-seek-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	userFile := filepath.Join(tmpDir, "users.txt")
+	passFile := filepath.Join(tmpDir, "passwords.txt")
+	if err := os.WriteFile(userFile, []byte("user0\nuser1\nuser2\n"), 0644); err != nil {
+		t.Fatalf("Failed to write user file: %v", err)
+	}
+	if err := os.WriteFile(passFile, []byte("pass0\npass1\npass2\npass3\n"), 0644); err != nil {
+		t.Fatalf("Failed to write password file: %v", err)
+	}
+
+	host := &Host{Host: "127.0.0.1", Port: 22, Service: "ssh"}
+	iter, err := NewCredentialIterator(host, userFile, passFile, "", "1.0", false, 0, 1)
+	if err != nil {
+		t.Fatalf("NewCredentialIterator failed: %v", err)
+	}
+	defer iter.Close()
+
+	// Seek to user index 1 ("user1"), password index 2 ("pass2"): every
+	// earlier (user, pass) pair for user0 and user1's first two passwords
+	// should be skipped.
+	if err := iter.SeekTo(1, 2); err != nil {
+		t.Fatalf("SeekTo failed: %v", err)
+	}
+
+	want := []string{
+		"user1:pass2", "user1:pass3",
+		"user2:pass0", "user2:pass1", "user2:pass2", "user2:pass3",
+	}
+	got := drainCredentials(t, iter)
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Index %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+}
+
+// TestSeekToPasswordOnly verifies SeekTo on a password-only iterator (VNC,
+// SNMP-style) skips straight to the requested password index.
+func TestSeekToPasswordOnly(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "brutespray-seek-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	passFile := filepath.Join(tmpDir, "passwords.txt")
+	if err := os.WriteFile(passFile, []byte("pass0\npass1\npass2\n"), 0644); err != nil {
+		t.Fatalf("Failed to write password file: %v", err)
+	}
+
+	host := &Host{Host: "127.0.0.1", Port: 5900, Service: "vnc"}
+	iter, err := NewCredentialIterator(host, "", passFile, "", "1.0", true, 0, 1)
+	if err != nil {
+		t.Fatalf("NewCredentialIterator failed: %v", err)
+	}
+	defer iter.Close()
+
+	if err := iter.SeekTo(0, 1); err != nil {
+		t.Fatalf("SeekTo failed: %v", err)
+	}
+
+	want := []string{":pass1", ":pass2"}
+	got := drainCredentials(t, iter)
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Index %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+}
+
+// TestSeekToCombo verifies SeekTo on a combo-file iterator skips straight
+// to the requested line.
+func TestSeekToCombo(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "brutespray-seek-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	comboFile := filepath.Join(tmpDir, "combos.txt")
+	if err := os.WriteFile(comboFile, []byte("userA:passA\nuserB:passB\nuserC:passC\n"), 0644); err != nil {
+		t.Fatalf("Failed to write combo file: %v", err)
+	}
+
+	host := &Host{Host: "127.0.0.1", Port: 22, Service: "ssh"}
+	iter, err := NewCredentialIterator(host, "", "", comboFile, "1.0", false, 0, 1)
+	if err != nil {
+		t.Fatalf("NewCredentialIterator failed: %v", err)
+	}
+	defer iter.Close()
+
+	if err := iter.SeekTo(1, 0); err != nil {
+		t.Fatalf("SeekTo failed: %v", err)
+	}
+
+	want := []string{"userB:passB", "userC:passC"}
+	got := drainCredentials(t, iter)
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Index %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+}
+
+// TestJournaledCredentialIteratorResumes verifies an iterator built with
+// NewJournaledCredentialIterator(resume=true) picks up one past the
+// highest position recorded in the journal by a prior run against the same
+// target.
+func TestJournaledCredentialIteratorResumes(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "brutespray-seek-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	userFile := filepath.Join(tmpDir, "users.txt")
+	passFile := filepath.Join(tmpDir, "passwords.txt")
+	if err := os.WriteFile(userFile, []byte("user0\nuser1\n"), 0644); err != nil {
+		t.Fatalf("Failed to write user file: %v", err)
+	}
+	if err := os.WriteFile(passFile, []byte("pass0\npass1\npass2\n"), 0644); err != nil {
+		t.Fatalf("Failed to write password file: %v", err)
+	}
+	journalPath := filepath.Join(tmpDir, "attempts.journal")
+
+	host := &Host{Host: "127.0.0.1", Port: 22, Service: "ssh"}
+
+	// First run: attempt a couple of credentials, then stop (simulating a
+	// crash) without exhausting the iterator.
+	first, err := NewJournaledCredentialIterator(host, userFile, passFile, "", "1.0", false, 0, 1, journalPath, false)
+	if err != nil {
+		t.Fatalf("NewJournaledCredentialIterator failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, _, ok := first.Next(); !ok {
+			t.Fatalf("Expected a credential on attempt %d", i)
+		}
+	}
+	if err := first.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// Second run: resume from the journal and make sure it doesn't replay
+	// user0:pass0 or user0:pass1.
+	second, err := NewJournaledCredentialIterator(host, userFile, passFile, "", "1.0", false, 0, 1, journalPath, true)
+	if err != nil {
+		t.Fatalf("NewJournaledCredentialIterator (resume) failed: %v", err)
+	}
+	defer second.Close()
+
+	got := drainCredentials(t, second)
+	want := []string{"user0:pass2", "user1:pass0", "user1:pass1", "user1:pass2"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Index %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+}
+
+// TestJournaledCredentialIteratorResumesComboMode verifies resuming a
+// combo-mode journal skips past the last-attempted line instead of replaying
+// it. LatestJournalCursor reports combo mode's cursor as (lastComboOrdinal,
+// 0) - the already-attempted line's own 0-based index, not a completed
+// count - so the resume path needs its own "+1" placement rather than the
+// passIdx+1 standard/password-only modes use.
+func TestJournaledCredentialIteratorResumesComboMode(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "brutespray-seek-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	comboFile := filepath.Join(tmpDir, "combos.txt")
+	if err := os.WriteFile(comboFile, []byte("user0:pass0\nuser0:pass1\nuser1:pass0\nuser1:pass1\n"), 0644); err != nil {
+		t.Fatalf("Failed to write combo file: %v", err)
+	}
+	journalPath := filepath.Join(tmpDir, "attempts.journal")
+
+	host := &Host{Host: "127.0.0.1", Port: 22, Service: "ssh"}
+
+	// First run: attempt a couple of combo lines, then stop (simulating a
+	// crash) without exhausting the iterator.
+	first, err := NewJournaledCredentialIterator(host, "", "", comboFile, "1.0", false, 0, 1, journalPath, false)
+	if err != nil {
+		t.Fatalf("NewJournaledCredentialIterator failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, _, ok := first.Next(); !ok {
+			t.Fatalf("Expected a credential on attempt %d", i)
+		}
+	}
+	if err := first.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// Second run: resume from the journal and make sure it doesn't replay
+	// user0:pass1, the last line attempted before the simulated crash.
+	second, err := NewJournaledCredentialIterator(host, "", "", comboFile, "1.0", false, 0, 1, journalPath, true)
+	if err != nil {
+		t.Fatalf("NewJournaledCredentialIterator (resume) failed: %v", err)
+	}
+	defer second.Close()
+
+	got := drainCredentials(t, second)
+	want := []string{"user1:pass0", "user1:pass1"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Index %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+}
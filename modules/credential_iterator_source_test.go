@@ -0,0 +1,326 @@
+package modules
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// TestCredentialIteratorMemPasswordSource verifies CredentialIterator itself
+// - not just the chunking/counting call sites - resolves a non-file
+// WordlistSource spec (here mem://, which needs no network) for the
+// password side, exercising the same ResolveWordlistSource/openSourceAt
+// path an http(s):// or stdin spec would take.
+func TestCredentialIteratorMemPasswordSource(t *testing.T) {
+	const key = "credential-iterator-mem-passwords"
+	RegisterMemWordlist(key, []byte("pass0\npass1\npass2\n"))
+	defer UnregisterMemWordlist(key)
+
+	host := &Host{Host: "127.0.0.1", Port: 22, Service: "ssh"}
+	iter, err := NewCredentialIterator(host, "alice", "mem://"+key, "", "1.0", false, 0, 1)
+	if err != nil {
+		t.Fatalf("NewCredentialIterator failed: %v", err)
+	}
+	defer iter.Close()
+
+	got := drainCredentials(t, iter)
+	want := []string{"alice:pass0", "alice:pass1", "alice:pass2"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Index %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+}
+
+// TestCredentialIteratorMemUserSourceRestartsAcrossUsers verifies a
+// mem://-backed user list moving past its last user, combined with a
+// mem://-backed password list, still resets and replays the password list
+// correctly for every user - the same resetPasswords path a file-backed
+// password list exercises, now against a non-file source.
+func TestCredentialIteratorMemUserSourceRestartsAcrossUsers(t *testing.T) {
+	const userKey = "credential-iterator-mem-users"
+	const passKey = "credential-iterator-mem-passwords-2"
+	RegisterMemWordlist(userKey, []byte("user0\nuser1\n"))
+	RegisterMemWordlist(passKey, []byte("pass0\npass1\n"))
+	defer UnregisterMemWordlist(userKey)
+	defer UnregisterMemWordlist(passKey)
+
+	host := &Host{Host: "127.0.0.1", Port: 22, Service: "ssh"}
+	iter, err := NewCredentialIterator(host, "mem://"+userKey, "mem://"+passKey, "", "1.0", false, 0, 1)
+	if err != nil {
+		t.Fatalf("NewCredentialIterator failed: %v", err)
+	}
+	defer iter.Close()
+
+	got := drainCredentials(t, iter)
+	want := []string{"user0:pass0", "user0:pass1", "user1:pass0", "user1:pass1"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Index %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+}
+
+// TestGetUsersAndPasswordsReadsMemSources verifies GetUsersAndPasswords -
+// the one call site the review flagged as still using IsFile/ReadUsersFromFile
+// directly - now resolves non-file WordlistSource specs too.
+func TestGetUsersAndPasswordsReadsMemSources(t *testing.T) {
+	const userKey = "calc-mem-users"
+	const passKey = "calc-mem-passwords"
+	RegisterMemWordlist(userKey, []byte("admin\nroot\n"))
+	RegisterMemWordlist(passKey, []byte("hunter2\nletmein\n"))
+	defer UnregisterMemWordlist(userKey)
+	defer UnregisterMemWordlist(passKey)
+
+	host := &Host{Host: "127.0.0.1", Port: 22, Service: "ssh"}
+	users, passwords := GetUsersAndPasswords(host, "mem://"+userKey, "mem://"+passKey, "1.0")
+
+	wantUsers := []string{"admin", "root"}
+	wantPasswords := []string{"hunter2", "letmein"}
+	if fmt.Sprint(users) != fmt.Sprint(wantUsers) {
+		t.Errorf("Expected users %v, got %v", wantUsers, users)
+	}
+	if fmt.Sprint(passwords) != fmt.Sprint(wantPasswords) {
+		t.Errorf("Expected passwords %v, got %v", wantPasswords, passwords)
+	}
+}
+
+// TestCredentialIteratorStdinPasswordSource verifies a "stdin" password spec
+// resolves through the same path, using os.Pipe to stand in for stdin
+// without depending on the test process's own standard input.
+func TestCredentialIteratorStdinPasswordSource(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	origStdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = origStdin }()
+
+	go func() {
+		fmt.Fprint(w, "pass0\npass1\n")
+		w.Close()
+	}()
+
+	host := &Host{Host: "127.0.0.1", Port: 22, Service: "ssh"}
+	iter, err := NewCredentialIterator(host, "alice", "stdin", "", "1.0", false, 0, 1)
+	if err != nil {
+		t.Fatalf("NewCredentialIterator failed: %v", err)
+	}
+	defer iter.Close()
+
+	got := drainCredentials(t, iter)
+	want := []string{"alice:pass0", "alice:pass1"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Index %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+}
+
+// TestCredentialIteratorStdinPasswordSourceAcrossMultipleUsers verifies a
+// stdin-backed password list is tried in full against every user, not just
+// the first - stdin can only be read once, so resetPasswords's normal
+// reopen-from-scratch approach would otherwise hit an already-exhausted
+// source on the second user.
+func TestCredentialIteratorStdinPasswordSourceAcrossMultipleUsers(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	origStdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = origStdin }()
+
+	go func() {
+		fmt.Fprint(w, "pass0\npass1\n")
+		w.Close()
+	}()
+
+	userFile, err := os.CreateTemp("", "brutespray-stdin-users-*.txt")
+	if err != nil {
+		t.Fatalf("Failed to create user file: %v", err)
+	}
+	defer os.Remove(userFile.Name())
+	fmt.Fprint(userFile, "user0\nuser1\n")
+	userFile.Close()
+
+	host := &Host{Host: "127.0.0.1", Port: 22, Service: "ssh"}
+	iter, err := NewCredentialIterator(host, userFile.Name(), "stdin", "", "1.0", false, 0, 1)
+	if err != nil {
+		t.Fatalf("NewCredentialIterator failed: %v", err)
+	}
+	defer iter.Close()
+
+	got := drainCredentials(t, iter)
+	want := []string{"user0:pass0", "user0:pass1", "user1:pass0", "user1:pass1"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Index %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+}
+
+// TestCredentialIteratorHTTPPasswordSourceReopensFromCache verifies that
+// once an HTTP(S) password source has been downloaded, every per-user reset
+// reopens it from the local cache file instead of issuing another request
+// against the origin server.
+func TestCredentialIteratorHTTPPasswordSourceReopensFromCache(t *testing.T) {
+	var requests int32
+	content := []byte("pass0\npass1\n")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	userFile, err := os.CreateTemp("", "brutespray-http-users-*.txt")
+	if err != nil {
+		t.Fatalf("Failed to create user file: %v", err)
+	}
+	defer os.Remove(userFile.Name())
+	fmt.Fprint(userFile, "user0\nuser1\nuser2\n")
+	userFile.Close()
+
+	url := server.URL + "/wordlist.txt"
+	host := &Host{Host: "127.0.0.1", Port: 22, Service: "ssh"}
+	iter, err := NewCredentialIterator(host, userFile.Name(), url, "", "1.0", false, 0, 1)
+	if err != nil {
+		t.Fatalf("NewCredentialIterator failed: %v", err)
+	}
+	cachePath := HTTPSource{URL: url}.cachePath()
+	defer os.Remove(cachePath)
+	defer iter.Close()
+
+	got := drainCredentials(t, iter)
+	want := []string{
+		"user0:pass0", "user0:pass1",
+		"user1:pass0", "user1:pass1",
+		"user2:pass0", "user2:pass1",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Index %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("Expected exactly 1 request to the origin server across all 3 users, got %d", n)
+	}
+}
+
+// TestCredentialIteratorRejectsDualStdin verifies NewCredentialIterator
+// refuses a "stdin" user spec combined with a "stdin" password spec instead
+// of silently racing two reads over the same os.Stdin and yielding nothing.
+func TestCredentialIteratorRejectsDualStdin(t *testing.T) {
+	host := &Host{Host: "127.0.0.1", Port: 22, Service: "ssh"}
+	iter, err := NewCredentialIterator(host, "stdin", "stdin", "", "1.0", false, 0, 1)
+	if err != nil {
+		t.Fatalf("NewCredentialIterator failed: %v", err)
+	}
+	defer iter.Close()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	origStderr := os.Stderr
+	os.Stderr = w
+	_, _, ok := iter.Next()
+	os.Stderr = origStderr
+	w.Close()
+
+	var captured strings.Builder
+	buf := make([]byte, 4096)
+	for {
+		n, readErr := r.Read(buf)
+		captured.Write(buf[:n])
+		if readErr != nil {
+			break
+		}
+	}
+
+	if ok {
+		t.Fatal("Expected Next to fail for user and password both set to stdin, got a credential")
+	}
+	if !strings.Contains(captured.String(), "cannot both be read from stdin") {
+		t.Errorf("Expected an error about reading from stdin, got: %s", captured.String())
+	}
+}
+
+// TestCredentialIteratorPasswordOnlyStdinNotRejected verifies the dual-stdin
+// guard doesn't fire in password-only mode (VNC, SNMP): ci.user there is
+// never opened as a source - it's fixed to a single empty user - so "stdin"
+// left over as the user argument poses no real race with a stdin password.
+func TestCredentialIteratorPasswordOnlyStdinNotRejected(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	origStdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = origStdin }()
+
+	go func() {
+		fmt.Fprint(w, "pass0\npass1\n")
+		w.Close()
+	}()
+
+	host := &Host{Host: "127.0.0.1", Port: 5900, Service: "vnc"}
+	iter, err := NewCredentialIterator(host, "stdin", "stdin", "", "1.0", true, 0, 1)
+	if err != nil {
+		t.Fatalf("NewCredentialIterator failed: %v", err)
+	}
+	defer iter.Close()
+
+	got := drainCredentials(t, iter)
+	want := []string{":pass0", ":pass1"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Index %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+}
+
+// TestGetUsersAndPasswordsRejectsDualStdin verifies GetUsersAndPasswords
+// refuses -u stdin -p stdin rather than racing two goroutines over the same
+// os.Stdin. Run in a subprocess since the error path calls os.Exit(1).
+func TestGetUsersAndPasswordsRejectsDualStdin(t *testing.T) {
+	if os.Getenv("BRUTESPRAY_DUAL_STDIN_SUBPROCESS") == "1" {
+		GetUsersAndPasswords(&Host{Host: "127.0.0.1", Port: 22, Service: "ssh"}, "stdin", "stdin", "1.0")
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=TestGetUsersAndPasswordsRejectsDualStdin")
+	cmd.Env = append(os.Environ(), "BRUTESPRAY_DUAL_STDIN_SUBPROCESS=1")
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("Expected subprocess to exit non-zero, got success with output: %s", output)
+	}
+	if !strings.Contains(string(output), "cannot both be read from stdin") {
+		t.Errorf("Expected an error about reading from stdin, got: %s", output)
+	}
+}
@@ -0,0 +1,185 @@
+package modules
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// journalFsyncInterval is how many records are appended to a
+// CredentialJournal between fsyncs, batching durability writes the same way
+// checkpointing batches JSON writes (see DefaultCheckpointInterval) - an
+// fsync per attempt would make a fast target bottleneck on disk I/O.
+const journalFsyncInterval = 1000
+
+// Status values recorded in a journal line. The iterator itself only ever
+// records JournalStatusAttempted when it appends a line for a credential it
+// just yielded; JournalStatusSuccess/JournalStatusFailure exist for a caller
+// that wants to append a further line with the actual login outcome via
+// CredentialJournal.Record once it knows it.
+const (
+	JournalStatusAttempted = "attempted"
+	JournalStatusSuccess   = "success"
+	JournalStatusFailure   = "failure"
+)
+
+// CredentialJournal is an append-only, crash-safe record of credential
+// attempts, one line per attempt in a compact shadow-style format:
+//
+//	host:port:service:userIndex-passIndex:status
+//
+// Unlike Checkpoint (see checkpoint.go), which overwrites a single file
+// with only the latest cursor, a journal line is never rewritten once
+// written, so a process killed mid-write loses at most its last unsynced
+// batch rather than corrupting the whole file - and the same journal file
+// can be shared across multiple hosts, since every line names the target
+// it belongs to.
+type CredentialJournal struct {
+	file       *os.File
+	writer     *bufio.Writer
+	sinceFsync int
+}
+
+// OpenCredentialJournal opens path for appending, creating it if it doesn't
+// already exist.
+func OpenCredentialJournal(path string) (*CredentialJournal, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening credential journal: %w", err)
+	}
+
+	return &CredentialJournal{
+		file:   f,
+		writer: bufio.NewWriter(f),
+	}, nil
+}
+
+// Record appends one attempt to the journal, flushing to disk every
+// journalFsyncInterval records so a crash loses at most a small, bounded
+// batch of progress instead of everything since the journal was opened.
+func (j *CredentialJournal) Record(host string, port int, service string, userIndex, passIndex int64, status string) error {
+	line := encodeJournalLine(host, port, service, userIndex, passIndex, status)
+	if _, err := j.writer.WriteString(line); err != nil {
+		return fmt.Errorf("error writing journal record: %w", err)
+	}
+
+	j.sinceFsync++
+	if j.sinceFsync >= journalFsyncInterval {
+		return j.Flush()
+	}
+	return nil
+}
+
+// Flush writes any buffered records and fsyncs the journal file.
+func (j *CredentialJournal) Flush() error {
+	if err := j.writer.Flush(); err != nil {
+		return fmt.Errorf("error flushing credential journal: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("error syncing credential journal: %w", err)
+	}
+	j.sinceFsync = 0
+	return nil
+}
+
+// Close flushes and closes the journal file.
+func (j *CredentialJournal) Close() error {
+	flushErr := j.Flush()
+	if err := j.file.Close(); err != nil {
+		return fmt.Errorf("error closing credential journal: %w", err)
+	}
+	return flushErr
+}
+
+func encodeJournalLine(host string, port int, service string, userIndex, passIndex int64, status string) string {
+	return fmt.Sprintf("%s:%d:%s:%d-%d:%s\n", host, port, service, userIndex, passIndex, status)
+}
+
+// journalRecord is one parsed line of a credential journal.
+type journalRecord struct {
+	host      string
+	port      int
+	service   string
+	userIndex int64
+	passIndex int64
+	status    string
+}
+
+func parseJournalLine(line string) (journalRecord, error) {
+	fields := strings.SplitN(line, ":", 5)
+	if len(fields) != 5 {
+		return journalRecord{}, fmt.Errorf("malformed journal line %q: expected 5 colon-separated fields", line)
+	}
+
+	port, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return journalRecord{}, fmt.Errorf("malformed journal line %q: bad port: %w", line, err)
+	}
+
+	cursor := strings.SplitN(fields[3], "-", 2)
+	if len(cursor) != 2 {
+		return journalRecord{}, fmt.Errorf("malformed journal line %q: bad cursor", line)
+	}
+	userIndex, err := strconv.ParseInt(cursor[0], 10, 64)
+	if err != nil {
+		return journalRecord{}, fmt.Errorf("malformed journal line %q: bad user index: %w", line, err)
+	}
+	passIndex, err := strconv.ParseInt(cursor[1], 10, 64)
+	if err != nil {
+		return journalRecord{}, fmt.Errorf("malformed journal line %q: bad pass index: %w", line, err)
+	}
+
+	return journalRecord{
+		host:      fields[0],
+		port:      port,
+		service:   fields[2],
+		userIndex: userIndex,
+		passIndex: passIndex,
+		status:    fields[4],
+	}, nil
+}
+
+// LatestJournalCursor scans journalPath for the highest-numbered
+// (userIndex, passIndex) recorded for host:port:service, so a resumed run
+// knows where to SeekTo. A missing journal file, or one with no records for
+// this target, is not an error: found is false and the caller should start
+// from the beginning. Malformed lines (e.g. a journal truncated mid-write
+// by a crash) are skipped with a warning rather than failing the scan.
+func LatestJournalCursor(journalPath, host string, port int, service string) (userIndex, passIndex int64, found bool, err error) {
+	f, err := os.Open(journalPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, 0, false, nil
+		}
+		return 0, 0, false, fmt.Errorf("error opening credential journal: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, DefaultScannerBufferSize), MaxLineLength)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		rec, parseErr := parseJournalLine(line)
+		if parseErr != nil {
+			fmt.Fprintf(os.Stderr, "[WARNING] Skipping malformed journal record: %v\n", parseErr)
+			continue
+		}
+		if rec.host != host || rec.port != port || rec.service != service {
+			continue
+		}
+		if !found || rec.userIndex > userIndex || (rec.userIndex == userIndex && rec.passIndex > passIndex) {
+			userIndex, passIndex = rec.userIndex, rec.passIndex
+			found = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, 0, false, fmt.Errorf("error reading credential journal: %w", err)
+	}
+
+	return userIndex, passIndex, found, nil
+}
@@ -0,0 +1,116 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCredentialJournalRecordAndReadBack(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "brutespray-journal-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "attempts.journal")
+	journal, err := OpenCredentialJournal(path)
+	if err != nil {
+		t.Fatalf("OpenCredentialJournal failed: %v", err)
+	}
+
+	if err := journal.Record("10.0.0.1", 22, "ssh", 0, 0, JournalStatusAttempted); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if err := journal.Record("10.0.0.1", 22, "ssh", 0, 1, JournalStatusAttempted); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if err := journal.Record("10.0.0.1", 22, "ssh", 1, 0, JournalStatusAttempted); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if err := journal.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	userIdx, passIdx, found, err := LatestJournalCursor(path, "10.0.0.1", 22, "ssh")
+	if err != nil {
+		t.Fatalf("LatestJournalCursor failed: %v", err)
+	}
+	if !found {
+		t.Fatal("Expected a cursor to be found")
+	}
+	if userIdx != 1 || passIdx != 0 {
+		t.Errorf("Expected cursor (1, 0), got (%d, %d)", userIdx, passIdx)
+	}
+}
+
+func TestCredentialJournalCursorPerTarget(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "brutespray-journal-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "attempts.journal")
+	journal, err := OpenCredentialJournal(path)
+	if err != nil {
+		t.Fatalf("OpenCredentialJournal failed: %v", err)
+	}
+	defer journal.Close()
+
+	if err := journal.Record("10.0.0.1", 22, "ssh", 5, 5, JournalStatusAttempted); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if err := journal.Record("10.0.0.2", 22, "ssh", 1, 1, JournalStatusAttempted); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if err := journal.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	if _, _, found, err := LatestJournalCursor(path, "10.0.0.3", 22, "ssh"); err != nil {
+		t.Fatalf("LatestJournalCursor failed: %v", err)
+	} else if found {
+		t.Error("Expected no cursor for a host that was never journaled")
+	}
+
+	userIdx, passIdx, found, err := LatestJournalCursor(path, "10.0.0.2", 22, "ssh")
+	if err != nil {
+		t.Fatalf("LatestJournalCursor failed: %v", err)
+	}
+	if !found || userIdx != 1 || passIdx != 1 {
+		t.Errorf("Expected cursor (1, 1) for 10.0.0.2, got (%d, %d, found=%v)", userIdx, passIdx, found)
+	}
+}
+
+func TestCredentialJournalMissingFile(t *testing.T) {
+	_, _, found, err := LatestJournalCursor("/nonexistent/path/attempts.journal", "10.0.0.1", 22, "ssh")
+	if err != nil {
+		t.Fatalf("Expected a missing journal to not be an error, got: %v", err)
+	}
+	if found {
+		t.Error("Expected found=false for a missing journal")
+	}
+}
+
+func TestCredentialJournalSkipsMalformedLines(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "brutespray-journal-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "attempts.journal")
+	content := "not a valid line\n10.0.0.1:22:ssh:3-4:attempted\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+
+	userIdx, passIdx, found, err := LatestJournalCursor(path, "10.0.0.1", 22, "ssh")
+	if err != nil {
+		t.Fatalf("LatestJournalCursor failed: %v", err)
+	}
+	if !found || userIdx != 3 || passIdx != 4 {
+		t.Errorf("Expected cursor (3, 4), got (%d, %d, found=%v)", userIdx, passIdx, found)
+	}
+}
@@ -0,0 +1,108 @@
+package modules
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPackAndUnpackWordlistRoundTrip(t *testing.T) {
+	entries := []byte("password1\npassword2\nhunter2\n")
+
+	var buf bytes.Buffer
+	if err := PackWordlist(&buf, entries, "correct-horse-battery-staple", PEMWordlistBlockType); err != nil {
+		t.Fatalf("PackWordlist failed: %v", err)
+	}
+
+	if !bytes.HasPrefix(buf.Bytes(), []byte("-----BEGIN "+PEMWordlistBlockType+"-----")) {
+		t.Fatalf("Expected PEM-armored output, got: %s", buf.String())
+	}
+
+	plaintext, err := UnpackWordlist(&buf, "correct-horse-battery-staple")
+	if err != nil {
+		t.Fatalf("UnpackWordlist failed: %v", err)
+	}
+	if !bytes.Equal(plaintext, entries) {
+		t.Errorf("Expected decrypted entries to match, got %q, want %q", plaintext, entries)
+	}
+}
+
+func TestUnpackWordlistWrongPassphrase(t *testing.T) {
+	entries := []byte("password1\npassword2\n")
+
+	var buf bytes.Buffer
+	if err := PackWordlist(&buf, entries, "the-real-passphrase", PEMWordlistBlockType); err != nil {
+		t.Fatalf("PackWordlist failed: %v", err)
+	}
+
+	if _, err := UnpackWordlist(&buf, "a-wrong-passphrase"); err == nil {
+		t.Error("Expected an error when unpacking with the wrong passphrase")
+	}
+}
+
+func TestUnpackWordlistRejectsFutureVersion(t *testing.T) {
+	entries := []byte("password1\n")
+
+	var buf bytes.Buffer
+	if err := PackWordlist(&buf, entries, "passphrase", PEMWordlistBlockType); err != nil {
+		t.Fatalf("PackWordlist failed: %v", err)
+	}
+
+	tampered := bytes.Replace(buf.Bytes(), []byte("Version: 1"), []byte("Version: 99"), 1)
+
+	if _, err := UnpackWordlist(bytes.NewReader(tampered), "passphrase"); err == nil {
+		t.Error("Expected an error when unpacking an unsupported version")
+	}
+}
+
+// TestOpenWordlistEncrypted verifies that openWordlist (and therefore the
+// CredentialIterator pipeline built on top of it) transparently decrypts a
+// PEM-armored wordlist on disk, resolving the passphrase from the
+// environment instead of prompting.
+func TestOpenWordlistEncrypted(t *testing.T) {
+	t.Setenv(encryptedWordlistPassphraseEnv, "field-test-passphrase")
+
+	tmpDir, err := os.MkdirTemp("", "brutespray-encrypted-wordlist-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	entries := []byte("alpha\nbravo\ncharlie\n")
+	path := filepath.Join(tmpDir, "passwords.txt.pem")
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create wordlist file: %v", err)
+	}
+	if err := PackWordlist(f, entries, "field-test-passphrase", PEMWordlistBlockType); err != nil {
+		f.Close()
+		t.Fatalf("PackWordlist failed: %v", err)
+	}
+	f.Close()
+
+	reader, size, err := openWordlist(path)
+	if err != nil {
+		t.Fatalf("openWordlist failed: %v", err)
+	}
+	defer reader.Close()
+
+	if size != int64(len(entries)) {
+		t.Errorf("Expected decrypted size %d, got %d", len(entries), size)
+	}
+
+	got := make([]byte, size)
+	if _, err := reader.Read(got); err != nil {
+		t.Fatalf("Failed to read decrypted wordlist: %v", err)
+	}
+	if !bytes.Equal(got, entries) {
+		t.Errorf("Expected decrypted content %q, got %q", entries, got)
+	}
+
+	if _, ok := reader.(interface {
+		Seek(offset int64, whence int) (int64, error)
+	}); !ok {
+		t.Error("Expected the decrypted wordlist reader to support seeking for checkpoint resume")
+	}
+}
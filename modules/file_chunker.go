@@ -10,12 +10,14 @@ import (
 )
 
 // FileChunkSize defines the maximum size for password file chunks (in bytes)
-// Default: 500 MB per chunk
-const FileChunkSize = 500 * 1024 * 1024
+// Default: 500 MB per chunk. Var rather than const so tests can shrink it to
+// exercise multi-chunk splitting against a test-sized fixture instead of a
+// multi-hundred-MB one.
+var FileChunkSize int64 = 500 * 1024 * 1024
 
-// LargeFileThreshold defines the file size threshold for automatic chunking (in bytes)
-// Default: 1 GB
-const LargeFileThreshold = 1 * 1024 * 1024 * 1024
+// LargeFileThreshold defines the file size threshold for automatic chunking
+// (in bytes). Default: 1 GB. Var for the same reason as FileChunkSize.
+var LargeFileThreshold int64 = 1 * 1024 * 1024 * 1024
 
 // DisableFileChunking is a global flag to disable automatic file chunking
 var DisableFileChunking = false
@@ -24,10 +26,92 @@ var DisableFileChunking = false
 type ChunkedFile struct {
 	OriginalPath string
 	ChunkPaths   []string
-	TempDir      string
-	ChunkSize    int64
-	IsChunked    bool
-	mutex        sync.Mutex
+
+	// TempDir is set when chunks were written to an ad-hoc, unshared
+	// directory (by newChunkedFileFromStream for a stdin or mem:// source,
+	// or by a test constructing a ChunkedFile directly); Cleanup just
+	// removes it outright. Chunking a file on disk instead uses
+	// CacheDir (see acquireChunks), which is refcounted since other
+	// brutespray processes may be sharing it.
+	TempDir string
+
+	// CacheDir is the shared, refcounted chunk cache directory under
+	// chunkCacheRoot() this ChunkedFile is holding a reference to, set by
+	// acquireChunks. Empty when chunking wasn't needed or used TempDir
+	// instead.
+	CacheDir  string
+	ChunkSize int64
+	IsChunked bool
+
+	// ChunkHashes is the sha256 of each entry in ChunkPaths, in the same
+	// order, set by acquireChunks. Chunk boundaries are content-defined (see
+	// buildChunks), so a chunk whose bytes are unaffected by an edit
+	// elsewhere in the wordlist keeps the same hash and is served from the
+	// shared blob store instead of being rewritten.
+	ChunkHashes []string
+
+	mutex sync.Mutex
+}
+
+// NewChunkedFileFromSource is the WordlistSource-based counterpart to
+// NewChunkedFile, for wordlists that aren't (yet) a plain path on disk:
+// stdin, an in-memory test fixture, or an HTTP(S) URL. A FileSource, or an
+// HTTPSource once downloaded, has a stable on-disk path and goes through the
+// normal NewChunkedFile path - so it gets the same shared, refcounted chunk
+// cache as any other file. StdinSource and MemSource have no such path and
+// are instead materialized into a single unshared chunk.
+func NewChunkedFileFromSource(source WordlistSource) (*ChunkedFile, error) {
+	if path, ok := sourceLocalPath(source); ok {
+		return NewChunkedFile(path)
+	}
+	return newChunkedFileFromStream(source)
+}
+
+// sourceLocalPath returns the on-disk path backing source, if any,
+// downloading an HTTPSource to its local cache first.
+func sourceLocalPath(source WordlistSource) (string, bool) {
+	switch s := source.(type) {
+	case FileSource:
+		return s.Path, true
+	case HTTPSource:
+		path, err := s.ensureDownloaded()
+		if err != nil {
+			return "", false
+		}
+		return path, true
+	default:
+		return "", false
+	}
+}
+
+// newChunkedFileFromStream materializes a source with no backing path
+// (stdin or a mem:// fixture) into a single unshared chunk under a fresh
+// TempDir, since there's no stable path to key a shared cache entry on.
+func newChunkedFileFromStream(source WordlistSource) (*ChunkedFile, error) {
+	reader, err := source.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", source.Name(), err)
+	}
+	defer reader.Close()
+
+	tmpDir, err := os.MkdirTemp("", "brutespray-stream-chunk-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+
+	chunkPath := filepath.Join(tmpDir, "chunk_0000")
+	if err := CopyReaderToFile(reader, chunkPath); err != nil {
+		os.RemoveAll(tmpDir)
+		return nil, fmt.Errorf("failed to materialize %s: %w", source.Name(), err)
+	}
+
+	return &ChunkedFile{
+		OriginalPath: source.Name(),
+		ChunkPaths:   []string{chunkPath},
+		TempDir:      tmpDir,
+		ChunkSize:    FileChunkSize,
+		IsChunked:    true,
+	}, nil
 }
 
 // NewChunkedFile creates a chunked file manager
@@ -44,75 +128,164 @@ func NewChunkedFile(filePath string) (*ChunkedFile, error) {
 		return cf, nil
 	}
 
-	// Get file size
-	fileInfo, err := os.Stat(filePath)
+	// Estimate the decompressed size so a compressed wordlist is chunked (or
+	// not) based on what it actually expands to, not its on-disk size.
+	reader, size, err := openWordlist(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to stat file: %w", err)
+		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
+	reader.Close()
 
-	// If file is small enough, don't chunk it
-	if fileInfo.Size() < LargeFileThreshold {
+	// size < 0 means the estimate isn't available (e.g. a bzip2 wordlist, or
+	// a zstd wordlist written without a recorded content size) - treat that
+	// the same as "large" and chunk it rather than risking an oversized
+	// single pass.
+	if size >= 0 && size < LargeFileThreshold {
 		cf.ChunkPaths = []string{filePath}
 		return cf, nil
 	}
 
-	// File is large, need to chunk it
-	fmt.Fprintf(os.Stderr, "[INFO] Large file detected (%d MB), creating chunks...\n", fileInfo.Size()/(1024*1024))
-	if err := cf.createChunks(); err != nil {
+	// File is large (or its size can't be estimated), need to chunk it
+	if size >= 0 {
+		fmt.Fprintf(os.Stderr, "[INFO] Large file detected (%d MB), creating chunks...\n", size/(1024*1024))
+	} else {
+		fmt.Fprintf(os.Stderr, "[INFO] Compressed file with unknown decompressed size, creating chunks...\n")
+	}
+	if err := cf.acquireChunks(); err != nil {
 		return nil, fmt.Errorf("failed to create chunks: %w", err)
 	}
 
 	return cf, nil
 }
 
-// createChunks splits the large file into smaller chunks
-func (cf *ChunkedFile) createChunks() error {
+// acquireChunks finds or builds the shared chunk cache directory for
+// cf.OriginalPath and registers this ChunkedFile as a holder of it, so two
+// concurrent brutespray processes chunking the same wordlist reuse one
+// cache entry instead of duplicating the work and disk usage.
+func (cf *ChunkedFile) acquireChunks() error {
 	cf.mutex.Lock()
 	defer cf.mutex.Unlock()
 
-	// Create temporary directory for chunks
-	tempDir, err := os.MkdirTemp("", "brutespray-chunks-*")
+	info, err := os.Stat(cf.OriginalPath)
+	if err != nil {
+		return fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	key := chunkCacheKey(cf.OriginalPath, info.Size(), info.ModTime().UnixNano())
+	cacheDir := filepath.Join(chunkCacheRoot(), key)
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return fmt.Errorf("failed to create chunk cache directory: %w", err)
+	}
+
+	// Hold the cache directory's lock for the whole find-or-build-then-
+	// register-as-holder sequence so two processes racing on the same
+	// wordlist can't both decide the cache is missing and chunk it twice.
+	lock, err := AcquireLock(cacheDir + ".lock")
+	if err != nil {
+		return fmt.Errorf("failed to acquire chunk cache lock: %w", err)
+	}
+	defer lock.Release()
+
+	manifest, err := loadChunkManifest(cacheDir)
 	if err != nil {
-		return fmt.Errorf("failed to create temp directory: %w", err)
+		fmt.Fprintf(os.Stderr, "[WARNING] Ignoring unusable chunk cache %s: %v\n", cacheDir, err)
+		manifest = nil
 	}
-	cf.TempDir = tempDir
+
+	if manifest != nil {
+		fmt.Fprintf(os.Stderr, "[INFO] Reusing cached chunks for %s from %s\n", cf.OriginalPath, cacheDir)
+	} else {
+		fmt.Fprintf(os.Stderr, "[INFO] No usable chunk cache for %s, creating chunks in %s\n", cf.OriginalPath, cacheDir)
+		manifest, err = cf.buildChunks(cacheDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := addChunkCacheRef(cacheDir); err != nil {
+		return fmt.Errorf("failed to register chunk cache holder: %w", err)
+	}
+
+	cf.CacheDir = cacheDir
+	cf.ChunkPaths = manifest.ChunkPaths
+	cf.ChunkHashes = manifest.SHA256
 	cf.IsChunked = true
+	return nil
+}
 
-	// Open the original file
-	file, err := os.Open(cf.OriginalPath)
+// buildChunks splits the original file into chunks and writes the completed
+// manifest under cacheDir. Callers must hold cacheDir's lock.
+//
+// Chunk boundaries are content-defined rather than a fixed byte budget: a
+// rolling hash is run continuously over the line bytes, and a boundary is
+// declared once the current chunk has reached minContentChunkSize and the
+// hash of the trailing rollingWindowSize bytes matches
+// contentChunkBoundaryMask (falling back to a hard cut at cf.ChunkSize if
+// that never happens). Because the boundary only depends on a small trailing
+// window, prepending or editing a line upstream shifts which chunk a later
+// boundary lands in but not whether it recurs - so the chunks downstream of
+// an edit come out byte-identical to the ones built before it, and
+// storeBlob's content-addressed store reuses them instead of rewriting.
+func (cf *ChunkedFile) buildChunks(cacheDir string) (*ChunkManifest, error) {
+	// Open the original file, transparently decompressing it if needed -
+	// chunks themselves are always written out as plain text.
+	reader, _, err := openWordlist(cf.OriginalPath)
 	if err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
+		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
-	defer file.Close()
+	defer reader.Close()
 
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(reader)
 	scanner.Buffer(make([]byte, 64*1024), 1024*1024) // 64KB buffer, 1MB max line length
 
 	chunkIndex := 0
 	var currentChunk *os.File
 	var currentSize int64
-	var chunkPaths []string
+	var currentLines int
+	chunker := newRollingChunker()
+	manifest := &ChunkManifest{}
+
+	closeCurrentChunk := func() error {
+		if currentChunk == nil {
+			return nil
+		}
+		tmpPath := currentChunk.Name()
+		if err := currentChunk.Close(); err != nil {
+			return fmt.Errorf("failed to close chunk file: %w", err)
+		}
+		sum, err := sha256File(tmpPath)
+		if err != nil {
+			return err
+		}
+		storedPath, err := storeBlob(tmpPath, sum)
+		if err != nil {
+			return fmt.Errorf("failed to store chunk blob: %w", err)
+		}
+		manifest.ChunkPaths = append(manifest.ChunkPaths, storedPath)
+		manifest.LineCounts = append(manifest.LineCounts, currentLines)
+		manifest.SHA256 = append(manifest.SHA256, sum)
+		return nil
+	}
 
 	for scanner.Scan() {
 		line := scanner.Text()
 		lineSize := int64(len(line) + 1) // +1 for newline
 
-		// Check if we need to start a new chunk
-		if currentChunk == nil || currentSize+lineSize > cf.ChunkSize {
-			// Close previous chunk if exists
-			if currentChunk != nil {
-				currentChunk.Close()
+		atMaxSize := currentSize+lineSize > cf.ChunkSize
+		atContentBoundary := currentSize >= minContentChunkSize && chunker.atBoundary()
+		if currentChunk == nil || atMaxSize || atContentBoundary {
+			if err := closeCurrentChunk(); err != nil {
+				return nil, err
 			}
 
-			// Create new chunk file
-			chunkPath := filepath.Join(cf.TempDir, fmt.Sprintf("chunk_%04d.txt", chunkIndex))
+			chunkPath := filepath.Join(cacheDir, fmt.Sprintf(".chunk_%04d.tmp", chunkIndex))
 			currentChunk, err = os.Create(chunkPath)
 			if err != nil {
-				return fmt.Errorf("failed to create chunk file: %w", err)
+				return nil, fmt.Errorf("failed to create chunk file: %w", err)
 			}
-			chunkPaths = append(chunkPaths, chunkPath)
 			chunkIndex++
 			currentSize = 0
+			currentLines = 0
 
 			fmt.Fprintf(os.Stderr, "[INFO] Created chunk %d: %s\n", chunkIndex, chunkPath)
 		}
@@ -120,43 +293,100 @@ func (cf *ChunkedFile) createChunks() error {
 		// Write line to current chunk
 		if _, err := fmt.Fprintln(currentChunk, line); err != nil {
 			currentChunk.Close()
-			return fmt.Errorf("failed to write to chunk: %w", err)
+			return nil, fmt.Errorf("failed to write to chunk: %w", err)
 		}
 		currentSize += lineSize
+		currentLines++
+		for i := 0; i < len(line); i++ {
+			chunker.roll(line[i])
+		}
+		chunker.roll('\n')
 	}
 
-	// Close last chunk
-	if currentChunk != nil {
-		currentChunk.Close()
+	if err := closeCurrentChunk(); err != nil {
+		return nil, err
 	}
 
 	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("error reading file: %w", err)
+		return nil, fmt.Errorf("error reading file: %w", err)
 	}
 
-	cf.ChunkPaths = chunkPaths
-	fmt.Fprintf(os.Stderr, "[INFO] Successfully created %d chunks\n", len(chunkPaths))
+	if err := manifest.Save(cacheDir); err != nil {
+		return nil, fmt.Errorf("failed to save chunk manifest: %w", err)
+	}
 
-	return nil
+	fmt.Fprintf(os.Stderr, "[INFO] Successfully created %d chunks\n", len(manifest.ChunkPaths))
+	return manifest, nil
 }
 
-// Cleanup removes temporary chunk files
+// Cleanup releases this ChunkedFile's hold on its chunk files. A CacheDir
+// (the normal production path) is refcounted and its directory is only
+// removed once every holder - potentially in a different process - has
+// released it. KeepChunks still releases this holder's own ref so the
+// refcount stays accurate for whoever comes next; it only changes what
+// happens when that release makes the count hit zero, skipping the
+// RemoveAll so a later run can reuse the cache instantly instead of
+// rebuilding it. Removing CacheDir only drops its manifest and refs; the
+// actual chunk content lives in the shared, content-addressed blob store
+// (see storeBlob) and is left in place since other cache entries may
+// reference the same chunks. A TempDir (used by callers that build a
+// ChunkedFile directly against an unshared directory) is always removed
+// outright.
 func (cf *ChunkedFile) Cleanup() error {
 	cf.mutex.Lock()
 	defer cf.mutex.Unlock()
 
-	if !cf.IsChunked || cf.TempDir == "" {
+	if !cf.IsChunked {
+		return nil
+	}
+
+	if cf.CacheDir != "" {
+		return cf.cleanupCacheDir()
+	}
+
+	if cf.TempDir == "" {
 		return nil
 	}
 
 	fmt.Fprintf(os.Stderr, "[INFO] Cleaning up temporary chunk files in %s\n", cf.TempDir)
-	err := os.RemoveAll(cf.TempDir)
-	if err != nil {
+	if err := os.RemoveAll(cf.TempDir); err != nil {
 		return fmt.Errorf("failed to remove temp directory: %w", err)
 	}
 
 	cf.TempDir = ""
 	cf.ChunkPaths = nil
+	cf.ChunkHashes = nil
+	return nil
+}
+
+// cleanupCacheDir releases this holder's reference to cf.CacheDir. Must be
+// called with cf.mutex held.
+func (cf *ChunkedFile) cleanupCacheDir() error {
+	lock, err := AcquireLock(cf.CacheDir + ".lock")
+	if err != nil {
+		return fmt.Errorf("failed to acquire chunk cache lock: %w", err)
+	}
+	defer lock.Release()
+
+	empty, err := removeChunkCacheRef(cf.CacheDir)
+	if err != nil {
+		return fmt.Errorf("failed to release chunk cache holder: %w", err)
+	}
+
+	if empty {
+		if KeepChunks {
+			fmt.Fprintf(os.Stderr, "[INFO] Last holder released chunk cache %s, keeping it (--keep-chunks)\n", cf.CacheDir)
+		} else {
+			fmt.Fprintf(os.Stderr, "[INFO] Last holder released chunk cache %s, removing it\n", cf.CacheDir)
+			if err := os.RemoveAll(cf.CacheDir); err != nil {
+				return fmt.Errorf("failed to remove chunk cache directory: %w", err)
+			}
+		}
+	}
+
+	cf.CacheDir = ""
+	cf.ChunkPaths = nil
+	cf.ChunkHashes = nil
 	return nil
 }
 
@@ -0,0 +1,42 @@
+package modules
+
+import (
+	"fmt"
+	"os"
+)
+
+// Lock is an OS-level advisory lock held against a file, used to serialize
+// access to a resource shared across concurrent brutespray processes (e.g.
+// a chunk cache directory). The underlying mechanism is flock(2) on Unix and
+// LockFileEx on Windows; see lockfile_unix.go and lockfile_windows.go.
+type Lock struct {
+	file *os.File
+	path string
+}
+
+// AcquireLock blocks until it holds an exclusive lock on path, creating the
+// lock file if it doesn't exist. The returned Lock must be released with
+// Release.
+func AcquireLock(path string) (*Lock, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening lock file %s: %w", path, err)
+	}
+
+	if err := lockFile(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error locking %s: %w", path, err)
+	}
+
+	return &Lock{file: file, path: path}, nil
+}
+
+// Release unlocks the file and closes its handle. The lock file itself is
+// left on disk so the next acquirer can reuse it.
+func (l *Lock) Release() error {
+	if err := unlockFile(l.file); err != nil {
+		l.file.Close()
+		return fmt.Errorf("error unlocking %s: %w", l.path, err)
+	}
+	return l.file.Close()
+}
@@ -0,0 +1,262 @@
+package modules
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// chunkResultBufferSize bounds how many lines a worker may read ahead of
+// the chunk that RunOrdered is currently draining, keeping memory use
+// proportional to concurrency rather than to the number of chunks.
+const chunkResultBufferSize = 4096
+
+// ParallelChunkRunner dispatches the chunks of a ChunkedFile across a pool
+// of goroutines, each driving its own bufio.Scanner over one chunk at a
+// time. It exists so a wordlist that has been split into many chunks (see
+// ChunkedFile) can be scanned with the parallelism a multi-gigabyte combo
+// list can actually use, instead of the strictly sequential ChunkIterator.
+type ParallelChunkRunner struct {
+	cf          *ChunkedFile
+	concurrency int
+}
+
+// NewParallelChunkRunner creates a runner over cf using up to concurrency
+// goroutines. concurrency <= 0 is treated as 1.
+func NewParallelChunkRunner(cf *ChunkedFile, concurrency int) *ParallelChunkRunner {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	return &ParallelChunkRunner{cf: cf, concurrency: concurrency}
+}
+
+// ChunkLine is one line read from a chunk, tagged with the chunk it came
+// from so RunOrdered can reassemble wordlist order and callers can report
+// progress per chunk.
+type ChunkLine struct {
+	ChunkIndex int
+	Line       string
+}
+
+// runWorkerPool spawns r.concurrency workers pulling chunk indices from a
+// jobs channel and invoking process for each. It cancels ctx and records
+// the first error (including a recovered panic) so every worker stops
+// promptly, and always runs cf.Cleanup() exactly once before returning.
+func (r *ParallelChunkRunner) runWorkerPool(ctx context.Context, process func(ctx context.Context, chunkIndex int, path string) error) (err error) {
+	chunks := r.cf.GetChunkPaths()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for i := range chunks {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var (
+		errOnce  sync.Once
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	fail := func(e error) {
+		errOnce.Do(func() {
+			firstErr = e
+			cancel()
+		})
+	}
+
+	for w := 0; w < r.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if p := recover(); p != nil {
+					fail(fmt.Errorf("panic processing chunk: %v", p))
+				}
+			}()
+
+			for idx := range jobs {
+				if ctx.Err() != nil {
+					return
+				}
+				if err := process(ctx, idx, chunks[idx]); err != nil {
+					fail(err)
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if cerr := r.cf.Cleanup(); cerr != nil {
+		fail(fmt.Errorf("error cleaning up chunk temp dir: %w", cerr))
+	}
+
+	return firstErr
+}
+
+// scanChunkFile opens path and calls onLine for every line, stopping early
+// if ctx is cancelled or onLine returns an error.
+func scanChunkFile(ctx context.Context, path string, onLine func(line string) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open chunk %s: %w", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024) // 64KB buffer, 1MB max line length
+
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := onLine(scanner.Text()); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// RunUnordered scans every chunk across up to r.concurrency goroutines and
+// invokes callback for each line as soon as it's read, with no guarantee
+// about which chunk's lines interleave with which. This gives the best
+// throughput since no worker ever waits on another.
+func (r *ParallelChunkRunner) RunUnordered(ctx context.Context, callback func(ChunkLine) error) error {
+	return r.runWorkerPool(ctx, func(ctx context.Context, chunkIndex int, path string) error {
+		return scanChunkFile(ctx, path, func(line string) error {
+			return callback(ChunkLine{ChunkIndex: chunkIndex, Line: line})
+		})
+	})
+}
+
+// RunOrdered scans every chunk across up to r.concurrency goroutines but
+// invokes callback in strict wordlist order (chunk 0 fully, then chunk 1,
+// and so on), by reading ahead into a bounded per-chunk buffer and draining
+// buffers in index order. A worker that gets chunkResultBufferSize lines
+// ahead of the chunk currently being drained blocks, which is the
+// back-pressure that keeps memory bounded regardless of chunk count.
+//
+// This doesn't reuse runWorkerPool: every buffer must be closed exactly
+// once no matter how a worker exits (finished, cancelled, or never
+// dispatched), which the generic pool's drain-after-the-fact shape can't
+// guarantee without deadlocking on an abandoned chunk.
+func (r *ParallelChunkRunner) RunOrdered(ctx context.Context, callback func(ChunkLine) error) error {
+	chunks := r.cf.GetChunkPaths()
+
+	buffers := make([]chan string, len(chunks))
+	closeOnce := make([]sync.Once, len(chunks))
+	for i := range chunks {
+		buffers[i] = make(chan string, chunkResultBufferSize)
+	}
+	closeBuffer := func(i int) {
+		closeOnce[i].Do(func() { close(buffers[i]) })
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for i := range chunks {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				// No worker will ever claim i..end now; close their
+				// buffers so the drain loop below doesn't block forever.
+				for j := i; j < len(chunks); j++ {
+					closeBuffer(j)
+				}
+				return
+			}
+		}
+	}()
+
+	var (
+		errOnce  sync.Once
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	fail := func(e error) {
+		errOnce.Do(func() {
+			firstErr = e
+			cancel()
+		})
+	}
+
+	for w := 0; w < r.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if p := recover(); p != nil {
+					fail(fmt.Errorf("panic processing chunk: %v", p))
+				}
+			}()
+
+			for idx := range jobs {
+				if ctx.Err() != nil {
+					closeBuffer(idx)
+					continue
+				}
+				err := scanChunkFile(ctx, chunks[idx], func(line string) error {
+					select {
+					case buffers[idx] <- line:
+						return nil
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				})
+				closeBuffer(idx)
+				if err != nil {
+					fail(err)
+				}
+			}
+		}()
+	}
+
+	// Drain concurrently with the workers above, in chunk index order. A
+	// later chunk's buffer simply won't have anything in it yet while an
+	// earlier one is still being drained, which is exactly the back-
+	// pressure that bounds memory use.
+	var drainErr error
+	drainDone := make(chan struct{})
+	go func() {
+		defer close(drainDone)
+		for i := range chunks {
+			for line := range buffers[i] {
+				if drainErr != nil {
+					continue
+				}
+				if err := callback(ChunkLine{ChunkIndex: i, Line: line}); err != nil {
+					drainErr = err
+					fail(err)
+				}
+			}
+		}
+	}()
+
+	wg.Wait()
+	cleanupErr := r.cf.Cleanup()
+	<-drainDone
+
+	if firstErr != nil {
+		return firstErr
+	}
+	if cleanupErr != nil {
+		return fmt.Errorf("error cleaning up chunk temp dir: %w", cleanupErr)
+	}
+	return drainErr
+}
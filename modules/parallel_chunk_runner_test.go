@@ -0,0 +1,187 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func writeLines(t *testing.T, path string, n int) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create %s: %v", path, err)
+	}
+	defer f.Close()
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(f, "line%06d\n", i)
+	}
+}
+
+// chunkedTestFile builds a ChunkedFile by hand, splitting lines across
+// several small chunk files directly rather than going through
+// NewChunkedFile - FileChunkSize is 500MB, far too large to exercise
+// multi-chunk behavior with a test-sized file.
+func chunkedTestFile(t *testing.T, lines int) *ChunkedFile {
+	t.Helper()
+	tmpDir, err := os.MkdirTemp("", "brutespray-parallel-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(tmpDir) })
+
+	const chunkCount = 5
+	linesPerChunk := (lines + chunkCount - 1) / chunkCount
+
+	var chunkPaths []string
+	remaining := lines
+	for i := 0; i < chunkCount && remaining > 0; i++ {
+		n := linesPerChunk
+		if n > remaining {
+			n = remaining
+		}
+		path := filepath.Join(tmpDir, fmt.Sprintf("chunk_%04d.txt", i))
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatalf("Failed to create chunk file: %v", err)
+		}
+		start := lines - remaining
+		for j := 0; j < n; j++ {
+			fmt.Fprintf(f, "line%06d\n", start+j)
+		}
+		f.Close()
+		chunkPaths = append(chunkPaths, path)
+		remaining -= n
+	}
+
+	return &ChunkedFile{
+		OriginalPath: filepath.Join(tmpDir, "wordlist.txt"),
+		ChunkPaths:   chunkPaths,
+		TempDir:      tmpDir,
+		IsChunked:    true,
+	}
+}
+
+// TestParallelChunkRunnerRunOrdered verifies RunOrdered reassembles chunks
+// in original wordlist order even though they're scanned concurrently.
+func TestParallelChunkRunnerRunOrdered(t *testing.T) {
+	const totalLines = 3000
+	cf := chunkedTestFile(t, totalLines)
+	if len(cf.ChunkPaths) < 2 {
+		t.Fatalf("Expected test file to be split into multiple chunks, got %d", len(cf.ChunkPaths))
+	}
+
+	runner := NewParallelChunkRunner(cf, 4)
+
+	var mu sync.Mutex
+	var got []string
+	err := runner.RunOrdered(context.Background(), func(cl ChunkLine) error {
+		mu.Lock()
+		got = append(got, cl.Line)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RunOrdered returned error: %v", err)
+	}
+
+	if len(got) != totalLines {
+		t.Fatalf("Expected %d lines, got %d", totalLines, len(got))
+	}
+	for i, line := range got {
+		want := fmt.Sprintf("line%06d", i)
+		if line != want {
+			t.Fatalf("Expected ordered line %d to be %q, got %q", i, want, line)
+		}
+	}
+
+	if cf.TempDir != "" {
+		t.Errorf("Expected chunk temp dir to be cleaned up after RunOrdered")
+	}
+}
+
+// TestParallelChunkRunnerRunUnordered verifies every line is seen exactly
+// once, without asserting anything about order.
+func TestParallelChunkRunnerRunUnordered(t *testing.T) {
+	const totalLines = 3000
+	cf := chunkedTestFile(t, totalLines)
+
+	runner := NewParallelChunkRunner(cf, 4)
+
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+	err := runner.RunUnordered(context.Background(), func(cl ChunkLine) error {
+		mu.Lock()
+		seen[cl.Line] = true
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RunUnordered returned error: %v", err)
+	}
+
+	if len(seen) != totalLines {
+		t.Fatalf("Expected %d unique lines, got %d", totalLines, len(seen))
+	}
+}
+
+// TestParallelChunkRunnerPropagatesFirstError ensures a callback error stops
+// the run and is returned, and that the chunk temp dir is still cleaned up.
+func TestParallelChunkRunnerPropagatesFirstError(t *testing.T) {
+	const totalLines = 3000
+	cf := chunkedTestFile(t, totalLines)
+	tempDir := cf.TempDir
+
+	runner := NewParallelChunkRunner(cf, 4)
+
+	boom := fmt.Errorf("boom")
+	err := runner.RunOrdered(context.Background(), func(cl ChunkLine) error {
+		return boom
+	})
+	if err != boom {
+		t.Fatalf("Expected the callback's error to propagate, got %v", err)
+	}
+
+	if tempDir != "" {
+		if _, statErr := os.Stat(tempDir); !os.IsNotExist(statErr) {
+			t.Errorf("Expected chunk temp dir %s to be removed after an error", tempDir)
+		}
+	}
+}
+
+func TestParallelChunkRunnerSortedChunkCoverage(t *testing.T) {
+	const totalLines = 500
+	cf := chunkedTestFile(t, totalLines)
+	chunkCount := len(cf.ChunkPaths)
+
+	runner := NewParallelChunkRunner(cf, 3)
+
+	var mu sync.Mutex
+	var indices []int
+	err := runner.RunUnordered(context.Background(), func(cl ChunkLine) error {
+		mu.Lock()
+		indices = append(indices, cl.ChunkIndex)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RunUnordered returned error: %v", err)
+	}
+
+	sort.Ints(indices)
+	if len(indices) != totalLines {
+		t.Fatalf("Expected %d chunk-tagged lines, got %d", totalLines, len(indices))
+	}
+	if indices[0] != 0 || indices[len(indices)-1] != chunkCount-1 {
+		t.Errorf("Expected chunk indices to span 0..%d, got %d..%d", chunkCount-1, indices[0], indices[len(indices)-1])
+	}
+	for i := 1; i < len(indices); i++ {
+		if step := indices[i] - indices[i-1]; step != 0 && step != 1 {
+			t.Errorf("Chunk index %d missing from the sorted coverage", indices[i-1]+1)
+		}
+	}
+}
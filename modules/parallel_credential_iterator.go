@@ -0,0 +1,147 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RateLimiter caps how often Wait returns to rate per second, shared across
+// all goroutines attacking the same host so a ParallelCredentialIterator
+// can fan work out across workers without overwhelming the target.
+type RateLimiter struct {
+	tokens chan struct{}
+	ticker *time.Ticker
+	done   chan struct{}
+}
+
+// NewRateLimiter creates a limiter that admits up to ratePerSecond calls to
+// Wait per second. ratePerSecond <= 0 means unlimited.
+func NewRateLimiter(ratePerSecond int) *RateLimiter {
+	if ratePerSecond <= 0 {
+		return nil
+	}
+
+	rl := &RateLimiter{
+		tokens: make(chan struct{}, ratePerSecond),
+		ticker: time.NewTicker(time.Second / time.Duration(ratePerSecond)),
+		done:   make(chan struct{}),
+	}
+
+	go func() {
+		for {
+			select {
+			case <-rl.ticker.C:
+				select {
+				case rl.tokens <- struct{}{}:
+				default:
+					// Buffer already full of unused tokens; drop this tick.
+				}
+			case <-rl.done:
+				return
+			}
+		}
+	}()
+
+	return rl
+}
+
+// Wait blocks until a token is available or ctx is cancelled. A nil
+// receiver is unlimited and always returns immediately.
+func (rl *RateLimiter) Wait(ctx context.Context) error {
+	if rl == nil {
+		return nil
+	}
+	select {
+	case <-rl.tokens:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stop releases the limiter's background ticker. A nil receiver is a no-op.
+func (rl *RateLimiter) Stop() {
+	if rl == nil {
+		return
+	}
+	rl.ticker.Stop()
+	close(rl.done)
+}
+
+// ParallelCredentialIterator fans user×password combinations for a single
+// host out across a pool of goroutines instead of trying them one at a
+// time, while still respecting an optional per-host rate limit. It is built
+// on top of ParallelChunkRunner for the password side so a chunked wordlist
+// is scanned with the same parallelism.
+type ParallelCredentialIterator struct {
+	host        *Host
+	users       []string
+	passwordsCF *ChunkedFile
+	concurrency int
+	limiter     *RateLimiter
+}
+
+// NewParallelCredentialIterator builds a parallel iterator for host. user is
+// resolved the same way CredentialIterator resolves it (file, literal
+// value, or default wordlist); password must name a file, which is chunked
+// via NewChunkedFile so large lists are split automatically. ratePerSecond
+// caps attempts/sec against this host; <= 0 means unlimited.
+func NewParallelCredentialIterator(host *Host, user, passwordFile, version string, isPasswordOnly bool, concurrency, ratePerSecond int) (*ParallelCredentialIterator, error) {
+	var users []string
+	if isPasswordOnly {
+		users = []string{""}
+	} else if user != "" {
+		if IsWordlistSourceSpec(user) {
+			fileUsers, err := readWordlistSourceLines(user)
+			if err != nil {
+				return nil, fmt.Errorf("error reading user source: %w", err)
+			}
+			users = fileUsers
+		} else {
+			users = []string{user}
+		}
+	} else {
+		users = GetUsersFromDefaultWordlist(version, host.Service)
+	}
+
+	cf, err := NewChunkedFile(passwordFile)
+	if err != nil {
+		return nil, fmt.Errorf("error chunking password file: %w", err)
+	}
+
+	return &ParallelCredentialIterator{
+		host:        host,
+		users:       users,
+		passwordsCF: cf,
+		concurrency: concurrency,
+		limiter:     NewRateLimiter(ratePerSecond),
+	}, nil
+}
+
+// Attempt is called once per user×password combination. A non-nil error
+// stops the run and is returned from Run once every in-flight attempt
+// drains.
+type Attempt func(ctx context.Context, user, password string) error
+
+// Run fans every user×password combination out across the iterator's
+// worker pool, rate-limiting each attempt, and guarantees the chunked
+// password file's temp directory is cleaned up even if attempt panics.
+func (p *ParallelCredentialIterator) Run(ctx context.Context, attempt Attempt) error {
+	defer p.limiter.Stop()
+
+	runner := NewParallelChunkRunner(p.passwordsCF, p.concurrency)
+
+	return runner.RunUnordered(ctx, func(cl ChunkLine) error {
+		password := cl.Line
+		for _, user := range p.users {
+			if err := p.limiter.Wait(ctx); err != nil {
+				return err
+			}
+			if err := attempt(ctx, user, password); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
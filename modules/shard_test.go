@@ -0,0 +1,145 @@
+package modules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestCredentialIteratorSharding verifies that splitting a standard-mode
+// iteration across 4 shards covers exactly the same set as the un-sharded
+// baseline, with no credential produced by more than one shard.
+func TestCredentialIteratorSharding(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "brutespray-shard-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	userFile := filepath.Join(tmpDir, "users.txt")
+	f, err := os.Create(userFile)
+	if err != nil {
+		t.Fatalf("Failed to create user file: %v", err)
+	}
+	for i := 0; i < 17; i++ {
+		fmt.Fprintf(f, "user%d\n", i)
+	}
+	f.Close()
+
+	passFile := filepath.Join(tmpDir, "passwords.txt")
+	f, err = os.Create(passFile)
+	if err != nil {
+		t.Fatalf("Failed to create password file: %v", err)
+	}
+	for i := 0; i < 53; i++ {
+		fmt.Fprintf(f, "pass%d\n", i)
+	}
+	f.Close()
+
+	host := &Host{Host: "127.0.0.1", Port: 22, Service: "ssh"}
+
+	baseline, err := NewCredentialIterator(host, userFile, passFile, "", "1.0", false, 0, 1)
+	if err != nil {
+		t.Fatalf("Failed to create baseline iterator: %v", err)
+	}
+	defer baseline.Close()
+
+	want := make(map[string]bool)
+	for {
+		u, p, ok := baseline.Next()
+		if !ok {
+			break
+		}
+		want[u+":"+p] = true
+	}
+
+	const shardCount = 4
+	got := make(map[string]bool)
+	for shardIndex := 0; shardIndex < shardCount; shardIndex++ {
+		iter, err := NewCredentialIterator(host, userFile, passFile, "", "1.0", false, shardIndex, shardCount)
+		if err != nil {
+			t.Fatalf("Failed to create shard %d iterator: %v", shardIndex, err)
+		}
+
+		for {
+			u, p, ok := iter.Next()
+			if !ok {
+				break
+			}
+			key := u + ":" + p
+			if got[key] {
+				t.Errorf("Credential %s produced by more than one shard", key)
+			}
+			got[key] = true
+		}
+		iter.Close()
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d total credentials across shards, got %d", len(want), len(got))
+	}
+	for key := range want {
+		if !got[key] {
+			t.Errorf("Credential %s missing from sharded union", key)
+		}
+	}
+}
+
+// TestCredentialIteratorShardingCombo verifies combo-mode sharding (which
+// shards by line number) also produces an exact, non-overlapping union.
+func TestCredentialIteratorShardingCombo(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "brutespray-shard-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	comboFile := filepath.Join(tmpDir, "combos.txt")
+	f, err := os.Create(comboFile)
+	if err != nil {
+		t.Fatalf("Failed to create combo file: %v", err)
+	}
+	const totalCombos = 97
+	for i := 0; i < totalCombos; i++ {
+		fmt.Fprintf(f, "user%d:pass%d\n", i, i)
+	}
+	f.Close()
+
+	host := &Host{Host: "127.0.0.1", Port: 22, Service: "ssh"}
+
+	const shardCount = 4
+	got := make(map[string]bool)
+	for shardIndex := 0; shardIndex < shardCount; shardIndex++ {
+		iter, err := NewCredentialIterator(host, "", "", comboFile, "1.0", false, shardIndex, shardCount)
+		if err != nil {
+			t.Fatalf("Failed to create shard %d iterator: %v", shardIndex, err)
+		}
+
+		for {
+			u, p, ok := iter.Next()
+			if !ok {
+				break
+			}
+			key := u + ":" + p
+			if got[key] {
+				t.Errorf("Combo %s produced by more than one shard", key)
+			}
+			got[key] = true
+		}
+		iter.Close()
+	}
+
+	if len(got) != totalCombos {
+		t.Fatalf("Expected %d total combos across shards, got %d", totalCombos, len(got))
+	}
+}
+
+// TestNewCredentialIteratorInvalidShard verifies an out-of-range shardIndex
+// is rejected rather than silently yielding nothing.
+func TestNewCredentialIteratorInvalidShard(t *testing.T) {
+	host := &Host{Host: "127.0.0.1", Port: 22, Service: "ssh"}
+	if _, err := NewCredentialIterator(host, "admin", "password", "", "1.0", false, 4, 4); err == nil {
+		t.Error("Expected an error for shardIndex == shardCount, got nil")
+	}
+}
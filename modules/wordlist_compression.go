@@ -0,0 +1,260 @@
+package modules
+
+import (
+	"bufio"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Magic byte prefixes used to sniff a wordlist's compression format without
+// trusting its file extension.
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	bzip2Magic = []byte{0x42, 0x5a} // "BZ"
+)
+
+// wordlistHeaderPeekSize must cover the longest magic prefix openWordlist
+// sniffs for - currently pemMagic ("-----BEGIN", see encrypted_wordlist.go).
+const wordlistHeaderPeekSize = 10
+
+// wordlistCloser wraps the decompression reader (if any) together with the
+// underlying *os.File so callers have a single io.ReadCloser to close,
+// regardless of which compression format was detected.
+type wordlistCloser struct {
+	io.Reader
+	file    *os.File
+	closers []io.Closer
+}
+
+// Seek only succeeds when the wrapped Reader is itself seekable, i.e. when
+// the wordlist wasn't compressed. Since wordlistCloser always has this
+// method, a type assertion to io.Seeker succeeds even for a compressed
+// wordlist; the error returned here is what reveals that case.
+func (w *wordlistCloser) Seek(offset int64, whence int) (int64, error) {
+	seeker, ok := w.Reader.(io.Seeker)
+	if !ok {
+		return 0, fmt.Errorf("compressed wordlist source does not support seeking")
+	}
+	return seeker.Seek(offset, whence)
+}
+
+func (w *wordlistCloser) Close() error {
+	var firstErr error
+	// Close in reverse order: decompressor(s) before the underlying file.
+	for i := len(w.closers) - 1; i >= 0; i-- {
+		if err := w.closers[i].Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if err := w.file.Close(); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
+
+// openWordlist opens path and transparently decodes it based on its
+// contents: gzip, zstd, or bzip2 compressed wordlists are wrapped in the
+// matching decompressor, and a PEM-armored encrypted wordlist (see
+// encrypted_wordlist.go) is decrypted after resolving a passphrase. The
+// returned size is the best available estimate of the *decompressed* size
+// (used for LargeFileThreshold decisions): the raw file size when not
+// compressed, the exact plaintext size for an encrypted wordlist, or -1 when
+// it can't be determined upfront for a compressed stream (in which case
+// callers should treat the file as large and stream it rather than
+// buffering).
+func openWordlist(path string) (io.ReadCloser, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error opening wordlist: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, fmt.Errorf("error statting wordlist: %w", err)
+	}
+
+	header := make([]byte, wordlistHeaderPeekSize)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		file.Close()
+		return nil, 0, fmt.Errorf("error reading wordlist header: %w", err)
+	}
+	header = header[:n]
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		return nil, 0, fmt.Errorf("error seeking wordlist: %w", err)
+	}
+
+	switch {
+	case hasPrefix(header, pemMagic):
+		// The encrypted reader is built entirely in memory from its own
+		// os.ReadFile, so the file handle opened above isn't needed for it.
+		file.Close()
+		return openEncryptedWordlist(path)
+
+	case hasPrefix(header, gzipMagic):
+		gzReader, err := gzip.NewReader(file)
+		if err != nil {
+			file.Close()
+			return nil, 0, fmt.Errorf("error opening gzip wordlist: %w", err)
+		}
+		size, ok := gzipISizeEstimate(file, info.Size())
+		if !ok {
+			size = -1
+		}
+		return &wordlistCloser{Reader: gzReader, file: file, closers: []io.Closer{gzReader}}, size, nil
+
+	case hasPrefix(header, zstdMagic):
+		zr, err := zstd.NewReader(file)
+		if err != nil {
+			file.Close()
+			return nil, 0, fmt.Errorf("error opening zstd wordlist: %w", err)
+		}
+		size := int64(-1)
+		if fcs, ok := zstdFrameContentSize(path); ok {
+			size = fcs
+		}
+		return &wordlistCloser{Reader: zr, file: file, closers: []io.Closer{zstdReaderCloser{zr}}}, size, nil
+
+	case hasPrefix(header, bzip2Magic):
+		bzReader := bzip2.NewReader(file)
+		// bzip2 exposes no cheap way to learn the decompressed size up
+		// front; fall back to streaming.
+		return &wordlistCloser{Reader: bzReader, file: file}, -1, nil
+
+	default:
+		return &wordlistCloser{Reader: file, file: file}, info.Size(), nil
+	}
+}
+
+// hasPrefix reports whether header starts with magic, tolerating header
+// being shorter than magic (a file smaller than the magic itself is never
+// a match, not a panic).
+func hasPrefix(header, magic []byte) bool {
+	if len(header) < len(magic) {
+		return false
+	}
+	for i, b := range magic {
+		if header[i] != b {
+			return false
+		}
+	}
+	return true
+}
+
+// zstdReaderCloser adapts zstd.Decoder's Close() (which returns nothing) to
+// io.Closer.
+type zstdReaderCloser struct {
+	dec *zstd.Decoder
+}
+
+func (z zstdReaderCloser) Close() error {
+	z.dec.Close()
+	return nil
+}
+
+// gzipISizeEstimate reads the ISIZE field from a gzip stream's trailer (the
+// uncompressed size modulo 2^32) without disturbing file's current read
+// position. It's only reliable for files smaller than 4GB, which covers
+// realistic wordlists; a failure here just means callers treat the file as
+// unsized and stream it.
+func gzipISizeEstimate(file *os.File, compressedSize int64) (int64, bool) {
+	if compressedSize < 8 {
+		return 0, false
+	}
+
+	trailer := make([]byte, 4)
+	if _, err := file.ReadAt(trailer, compressedSize-4); err != nil {
+		return 0, false
+	}
+
+	isize := uint32(trailer[0]) | uint32(trailer[1])<<8 | uint32(trailer[2])<<16 | uint32(trailer[3])<<24
+	return int64(isize), true
+}
+
+// zstdFrameContentSize parses just the zstd frame header (RFC 8878 §3.1.1)
+// to read the Frame_Content_Size field, which the encoder writes whenever
+// it knows the input size upfront (true for a plain file, as opposed to a
+// pipe). Returns ok=false when the field is absent rather than guessing.
+func zstdFrameContentSize(path string) (size int64, ok bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, false
+	}
+	defer file.Close()
+
+	header := make([]byte, 18) // magic(4) + descriptor(1) + window(1) + dictID(0-4) + fcs(0-8), worst case
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return 0, false
+	}
+	header = header[:n]
+	if len(header) < 5 || !hasPrefix(header, zstdMagic) {
+		return 0, false
+	}
+
+	fhd := header[4]
+	fcsFlag := (fhd >> 6) & 0x03
+	singleSegment := fhd&0x20 != 0 // bit 5
+	dictIDFlag := fhd & 0x03
+
+	pos := 5
+	if !singleSegment { // Window_Descriptor is present unless single-segment
+		pos++
+	}
+
+	dictIDBytes := map[byte]int{0: 0, 1: 1, 2: 2, 3: 4}[dictIDFlag]
+	pos += dictIDBytes
+
+	var fcsFieldSize int
+	switch fcsFlag {
+	case 0:
+		if singleSegment {
+			fcsFieldSize = 1
+		} else {
+			return 0, false // content size not recorded
+		}
+	case 1:
+		fcsFieldSize = 2
+	case 2:
+		fcsFieldSize = 4
+	case 3:
+		fcsFieldSize = 8
+	}
+
+	if pos+fcsFieldSize > len(header) {
+		return 0, false
+	}
+
+	var value uint64
+	for i := fcsFieldSize - 1; i >= 0; i-- {
+		value = value<<8 | uint64(header[pos+i])
+	}
+	if fcsFieldSize == 2 {
+		value += 256
+	}
+
+	return int64(value), true
+}
+
+// newWordlistScanner opens path (transparently decompressing it if needed)
+// and returns a ready-to-use, appropriately sized bufio.Scanner along with
+// the io.ReadCloser it must close when done.
+func newWordlistScanner(path string) (*bufio.Scanner, io.ReadCloser, error) {
+	reader, _, err := openWordlist(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, DefaultScannerBufferSize), MaxLineLength)
+	return scanner, reader, nil
+}
@@ -0,0 +1,163 @@
+package modules
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// TestOpenWordlistGzip verifies a gzip-compressed wordlist is transparently
+// decompressed and that its decompressed size is recovered from the ISIZE
+// trailer rather than the (smaller) compressed file size.
+func TestOpenWordlistGzip(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "brutespray-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	var plain bytes.Buffer
+	const lines = 2000
+	for i := 0; i < lines; i++ {
+		fmt.Fprintf(&plain, "password%d\n", i)
+	}
+
+	path := filepath.Join(tmpDir, "passwords.txt.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create gzip file: %v", err)
+	}
+	gw := gzip.NewWriter(f)
+	if _, err := gw.Write(plain.Bytes()); err != nil {
+		t.Fatalf("Failed to write gzip data: %v", err)
+	}
+	gw.Close()
+	f.Close()
+
+	reader, size, err := openWordlist(path)
+	if err != nil {
+		t.Fatalf("openWordlist returned error: %v", err)
+	}
+	defer reader.Close()
+
+	if size != int64(plain.Len()) {
+		t.Errorf("Expected decompressed size estimate %d, got %d", plain.Len(), size)
+	}
+
+	got, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Failed to read decompressed data: %v", err)
+	}
+	if !bytes.Equal(got, plain.Bytes()) {
+		t.Error("Decompressed content did not match original")
+	}
+}
+
+// TestOpenWordlistZstd verifies a zstd-compressed wordlist is transparently
+// decompressed and that Frame_Content_Size is recovered.
+func TestOpenWordlistZstd(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "brutespray-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	var plain bytes.Buffer
+	const lines = 2000
+	for i := 0; i < lines; i++ {
+		fmt.Fprintf(&plain, "password%d\n", i)
+	}
+
+	path := filepath.Join(tmpDir, "passwords.txt.zst")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create zstd file: %v", err)
+	}
+	zw, err := zstd.NewWriter(f)
+	if err != nil {
+		t.Fatalf("Failed to create zstd writer: %v", err)
+	}
+	if _, err := zw.Write(plain.Bytes()); err != nil {
+		t.Fatalf("Failed to write zstd data: %v", err)
+	}
+	zw.Close()
+	f.Close()
+
+	reader, size, err := openWordlist(path)
+	if err != nil {
+		t.Fatalf("openWordlist returned error: %v", err)
+	}
+	defer reader.Close()
+
+	if size != int64(plain.Len()) {
+		t.Errorf("Expected decompressed size estimate %d, got %d", plain.Len(), size)
+	}
+
+	got, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Failed to read decompressed data: %v", err)
+	}
+	if !bytes.Equal(got, plain.Bytes()) {
+		t.Error("Decompressed content did not match original")
+	}
+}
+
+// TestCredentialIteratorWithCompressedPasswordFile verifies the iterator can
+// stream credentials straight out of a gzip-compressed password file,
+// including a full pass over the list for a second user (resetPasswords).
+func TestCredentialIteratorWithCompressedPasswordFile(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "brutespray-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	passPath := filepath.Join(tmpDir, "passwords.txt.gz")
+	f, err := os.Create(passPath)
+	if err != nil {
+		t.Fatalf("Failed to create gzip file: %v", err)
+	}
+	gw := gzip.NewWriter(f)
+	const expectedPasswords = 300
+	for i := 0; i < expectedPasswords; i++ {
+		fmt.Fprintf(gw, "pass%d\n", i)
+	}
+	gw.Close()
+	f.Close()
+
+	userPath := filepath.Join(tmpDir, "users.txt")
+	if err := os.WriteFile(userPath, []byte("admin\nroot\n"), 0644); err != nil {
+		t.Fatalf("Failed to create user file: %v", err)
+	}
+
+	host := &Host{Host: "127.0.0.1", Port: 22, Service: "ssh"}
+
+	iter, err := NewCredentialIterator(host, userPath, passPath, "", "1.0", false, 0, 1)
+	if err != nil {
+		t.Fatalf("Failed to create iterator: %v", err)
+	}
+	defer iter.Close()
+
+	count := 0
+	for {
+		u, p, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if u == "" || p == "" {
+			t.Error("Got empty user or password")
+		}
+		count++
+	}
+
+	expected := 2 * expectedPasswords
+	if count != expected {
+		t.Errorf("Expected %d combinations, got %d", expected, count)
+	}
+}
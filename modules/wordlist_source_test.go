@@ -0,0 +1,275 @@
+package modules
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveWordlistSource(t *testing.T) {
+	cases := []struct {
+		spec string
+		want WordlistSource
+	}{
+		{"-", StdinSource{}},
+		{"stdin", StdinSource{}},
+		{"mem://fixture1", MemSource{Key: "fixture1"}},
+		{"http://example.com/rockyou.txt", HTTPSource{URL: "http://example.com/rockyou.txt"}},
+		{"https://example.com/rockyou.txt", HTTPSource{URL: "https://example.com/rockyou.txt"}},
+		{"/tmp/passwords.txt", FileSource{Path: "/tmp/passwords.txt"}},
+	}
+
+	for _, c := range cases {
+		got, err := ResolveWordlistSource(c.spec)
+		if err != nil {
+			t.Fatalf("ResolveWordlistSource(%q) returned error: %v", c.spec, err)
+		}
+		if got != c.want {
+			t.Errorf("ResolveWordlistSource(%q) = %#v, want %#v", c.spec, got, c.want)
+		}
+	}
+
+	if _, err := ResolveWordlistSource(""); err == nil {
+		t.Error("Expected an error resolving an empty spec")
+	}
+}
+
+func TestIsWordlistSourceSpec(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "brutespray-source-spec-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	existing := filepath.Join(tmpDir, "passwords.txt")
+	if err := os.WriteFile(existing, []byte("pass1\n"), 0644); err != nil {
+		t.Fatalf("Failed to write fixture file: %v", err)
+	}
+
+	specs := []string{"-", "stdin", "mem://anything", "http://host/list.txt", "https://host/list.txt", existing}
+	for _, spec := range specs {
+		if !IsWordlistSourceSpec(spec) {
+			t.Errorf("Expected IsWordlistSourceSpec(%q) to be true", spec)
+		}
+	}
+
+	if IsWordlistSourceSpec("not-a-real-password-or-path") {
+		t.Error("Expected a bare, non-existent path to not be a wordlist source spec")
+	}
+}
+
+// TestMemSourceRoundTrip verifies a fixture registered with
+// RegisterMemWordlist is readable back through the generic WordlistSource
+// interface, including via the "mem://" spec resolved by
+// ResolveWordlistSource.
+func TestMemSourceRoundTrip(t *testing.T) {
+	const key = "test-fixture-1"
+	data := []byte("alpha\nbravo\ncharlie\n")
+	RegisterMemWordlist(key, data)
+	defer UnregisterMemWordlist(key)
+
+	source, err := ResolveWordlistSource("mem://" + key)
+	if err != nil {
+		t.Fatalf("ResolveWordlistSource failed: %v", err)
+	}
+
+	if size, ok := source.Size(); !ok || size != int64(len(data)) {
+		t.Errorf("Expected size %d, got %d (ok=%v)", len(data), size, ok)
+	}
+
+	reader, err := source.Open()
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer reader.Close()
+
+	got, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Failed to read mem source: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("Expected %q, got %q", data, got)
+	}
+}
+
+// TestMemSourceMissingKey verifies opening an unregistered mem:// key fails
+// instead of silently returning an empty wordlist.
+func TestMemSourceMissingKey(t *testing.T) {
+	source := MemSource{Key: "does-not-exist"}
+	if _, err := source.Open(); err == nil {
+		t.Error("Expected an error opening an unregistered mem:// source")
+	}
+}
+
+// TestHTTPSourceDownloadAndCache verifies an HTTPSource downloads its
+// content and that a second, independent HTTPSource value for the same URL
+// reads back the identical content from the on-disk cache (rather than, say,
+// the cache key depending on anything but the URL).
+func TestHTTPSourceDownloadAndCache(t *testing.T) {
+	content := []byte("admin\nroot\nguest\n")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A fresh request (no Range) always gets the full content; a Range
+		// request past the end of the file (the cache is already complete)
+		// is rejected so the client knows not to append anything further.
+		if rng := r.Header.Get("Range"); rng != "" {
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	source := HTTPSource{URL: server.URL + "/wordlist.txt"}
+	defer os.Remove(source.cachePath())
+
+	reader, err := source.Open()
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	got, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		t.Fatalf("Failed to read downloaded content: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("Expected %q, got %q", content, got)
+	}
+
+	// Re-resolve the same URL as a second, independent HTTPSource value to
+	// make sure caching is keyed on the URL, not on reusing the same struct.
+	second := HTTPSource{URL: server.URL + "/wordlist.txt"}
+	reader2, err := second.Open()
+	if err != nil {
+		t.Fatalf("Second open failed: %v", err)
+	}
+	got2, err := io.ReadAll(reader2)
+	reader2.Close()
+	if err != nil {
+		t.Fatalf("Failed to read cached content: %v", err)
+	}
+	if !bytes.Equal(got2, content) {
+		t.Errorf("Expected cached content %q, got %q", content, got2)
+	}
+}
+
+// TestHTTPSourceResumesPartialDownload verifies a truncated local cache file
+// is resumed with a Range request instead of being redownloaded from
+// scratch.
+func TestHTTPSourceResumesPartialDownload(t *testing.T) {
+	content := []byte("line-one\nline-two\nline-three\nline-four\n")
+	var gotRange string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRange = r.Header.Get("Range")
+		if gotRange == "" {
+			w.Write(content)
+			return
+		}
+		var start int
+		fmt.Sscanf(gotRange, "bytes=%d-", &start)
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(content[start:])
+	}))
+	defer server.Close()
+
+	source := HTTPSource{URL: server.URL + "/resumable.txt"}
+	defer os.Remove(source.cachePath())
+
+	partial := content[:10]
+	if err := os.MkdirAll(filepath.Dir(source.cachePath()), 0755); err != nil {
+		t.Fatalf("Failed to create cache dir: %v", err)
+	}
+	if err := os.WriteFile(source.cachePath(), partial, 0644); err != nil {
+		t.Fatalf("Failed to seed partial download: %v", err)
+	}
+
+	reader, err := source.Open()
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	got, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		t.Fatalf("Failed to read resumed content: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("Expected resumed content %q, got %q", content, got)
+	}
+	if gotRange != fmt.Sprintf("bytes=%d-", len(partial)) {
+		t.Errorf("Expected a Range header resuming from byte %d, got %q", len(partial), gotRange)
+	}
+}
+
+// TestNewChunkedFileFromSourceMaterializesStream verifies a source with no
+// backing file (a mem:// fixture) is chunked via the unshared TempDir path
+// rather than the shared cache, and reads back correctly.
+func TestNewChunkedFileFromSourceMaterializesStream(t *testing.T) {
+	const key = "chunked-stream-fixture"
+	data := []byte("userA\nuserB\nuserC\n")
+	RegisterMemWordlist(key, data)
+	defer UnregisterMemWordlist(key)
+
+	cf, err := NewChunkedFileFromSource(MemSource{Key: key})
+	if err != nil {
+		t.Fatalf("NewChunkedFileFromSource failed: %v", err)
+	}
+	defer cf.Cleanup()
+
+	if cf.CacheDir != "" {
+		t.Error("Expected a stream-backed source to use TempDir, not the shared CacheDir")
+	}
+	if cf.TempDir == "" {
+		t.Error("Expected TempDir to be set for a stream-backed source")
+	}
+
+	var lines []string
+	if err := ReadLinesFromChunkedFile(cf, func(line string) error {
+		lines = append(lines, line)
+		return nil
+	}); err != nil {
+		t.Fatalf("ReadLinesFromChunkedFile failed: %v", err)
+	}
+
+	want := []string{"userA", "userB", "userC"}
+	if len(lines) != len(want) {
+		t.Fatalf("Expected %d lines, got %d: %v", len(want), len(lines), lines)
+	}
+	for i, line := range lines {
+		if line != want[i] {
+			t.Errorf("Line %d: expected %q, got %q", i, want[i], line)
+		}
+	}
+}
+
+// TestNewChunkedFileFromSourceUsesSharedCacheForFileSource verifies a
+// FileSource still goes through the normal NewChunkedFile path (and hence
+// the shared cache machinery) rather than being treated as a stream.
+func TestNewChunkedFileFromSourceUsesSharedCacheForFileSource(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "brutespray-source-filebacked-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "passwords.txt")
+	if err := os.WriteFile(path, []byte("pass1\npass2\n"), 0644); err != nil {
+		t.Fatalf("Failed to write fixture file: %v", err)
+	}
+
+	cf, err := NewChunkedFileFromSource(FileSource{Path: path})
+	if err != nil {
+		t.Fatalf("NewChunkedFileFromSource failed: %v", err)
+	}
+	defer cf.Cleanup()
+
+	if cf.IsChunked {
+		t.Error("Expected a small file to not require chunking")
+	}
+	if len(cf.ChunkPaths) != 1 || cf.ChunkPaths[0] != path {
+		t.Errorf("Expected the original path to be used directly, got %v", cf.ChunkPaths)
+	}
+}